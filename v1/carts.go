@@ -0,0 +1,118 @@
+package dairyclient
+
+import (
+	"time"
+
+	"github.com/dairycart/dairymodels/v1"
+)
+
+// Cart represents a shopper's in-progress order
+type Cart struct {
+	ID            uint64            `json:"id"`
+	UserID        uint64            `json:"user_id"`
+	LineItems     []CartLineItem    `json:"line_items"`
+	DiscountCodes []string          `json:"discount_codes"`
+	CreatedOn     time.Time         `json:"created_on"`
+	UpdatedOn     *models.Dairytime `json:"updated_on"`
+	ArchivedOn    *models.Dairytime `json:"archived_on"`
+}
+
+// CartLineItem represents a quantity of a single SKU within a cart
+type CartLineItem struct {
+	ID        uint64  `json:"id"`
+	CartID    uint64  `json:"cart_id"`
+	ProductID uint64  `json:"product_id"`
+	SKU       string  `json:"sku"`
+	Quantity  uint32  `json:"quantity"`
+	UnitPrice float32 `json:"unit_price"`
+	Total     float32 `json:"total"`
+}
+
+// CartTotals is the server's price calculation for a cart
+type CartTotals struct {
+	ItemCount uint32  `json:"item_count"`
+	Subtotal  float32 `json:"subtotal"`
+	Discount  float32 `json:"discount"`
+	Tax       float32 `json:"tax"`
+	Total     float32 `json:"total"`
+}
+
+// CartCreationInput is the body sent when creating a cart. UserID may be left empty for guest carts
+type CartCreationInput struct {
+	UserID uint64 `json:"user_id,omitempty"`
+}
+
+// CartLineItemInput is the body sent when adding or updating a line item
+type CartLineItemInput struct {
+	SKU      string `json:"sku,omitempty"`
+	Quantity uint32 `json:"quantity"`
+}
+
+// CartDiscountInput is the body sent when applying a discount code to a cart
+type CartDiscountInput struct {
+	Code string `json:"code"`
+}
+
+////////////////////////////////////////////////////////
+//                                                    //
+//                  Cart Functions                    //
+//                                                    //
+////////////////////////////////////////////////////////
+
+// CreateCart creates a new, empty cart
+func (dc *V1Client) CreateCart(nc CartCreationInput) (*Cart, error) {
+	u := dc.buildURL(nil, "cart")
+	return post[Cart](dc, u, nc)
+}
+
+// GetCart retrieves a cart with a given ID
+func (dc *V1Client) GetCart(cartID uint64) (*Cart, error) {
+	cartIDString := convertIDToString(cartID)
+	u := dc.buildURL(nil, "cart", cartIDString)
+	return get[Cart](dc, u)
+}
+
+// AddCartItem adds a quantity of a given SKU to a cart
+func (dc *V1Client) AddCartItem(cartID uint64, sku string, quantity uint32) (*Cart, error) {
+	cartIDString := convertIDToString(cartID)
+	u := dc.buildURL(nil, "cart", cartIDString, "items")
+
+	in := CartLineItemInput{SKU: sku, Quantity: quantity}
+	return post[Cart](dc, u, in)
+}
+
+// UpdateCartItem sets the quantity of a given SKU already in a cart
+func (dc *V1Client) UpdateCartItem(cartID uint64, sku string, quantity uint32) (*Cart, error) {
+	if err := requireIdentifier("sku", sku); err != nil {
+		return nil, err
+	}
+	cartIDString := convertIDToString(cartID)
+	u := dc.buildURL(nil, "cart", cartIDString, "item", sku)
+
+	in := CartLineItemInput{Quantity: quantity}
+	return patch[Cart](dc, u, in)
+}
+
+// RemoveCartItem removes a given SKU from a cart entirely
+func (dc *V1Client) RemoveCartItem(cartID uint64, sku string) error {
+	if err := requireIdentifier("sku", sku); err != nil {
+		return err
+	}
+	cartIDString := convertIDToString(cartID)
+	u := dc.buildURL(nil, "cart", cartIDString, "item", sku)
+	return dc.delete(u)
+}
+
+// ApplyDiscountCode applies a discount code to a cart
+func (dc *V1Client) ApplyDiscountCode(cartID uint64, code string) (*Cart, error) {
+	cartIDString := convertIDToString(cartID)
+	u := dc.buildURL(nil, "cart", cartIDString, "discount")
+	return post[Cart](dc, u, CartDiscountInput{Code: code})
+}
+
+// GetCartTotals retrieves the subtotal, discount, tax and total for a cart
+func (dc *V1Client) GetCartTotals(cartID uint64) (*CartTotals, error) {
+	cartIDString := convertIDToString(cartID)
+	u := dc.buildURL(nil, "cart", cartIDString, "totals")
+	return get[CartTotals](dc, u)
+}
@@ -0,0 +1,262 @@
+package dairyclient_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dairycart/dairyclient/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+////////////////////////////////////////////////////////
+//                                                    //
+//                Cart Function Tests                 //
+//                                                    //
+////////////////////////////////////////////////////////
+
+func buildNotFoundCartResponse(cartID uint64) string {
+	return fmt.Sprintf(`
+		{
+			"status": 404,
+			"message": "The cart you were looking for (cart ID '%d') does not exist"
+		}
+	`, cartID)
+}
+
+func TestCreateCart(t *testing.T) {
+	expectedBody := `
+		{
+			"user_id": 1
+		}
+	`
+	exampleInput := dairyclient.CartCreationInput{UserID: 1}
+
+	t.Run("normal usage", func(*testing.T) {
+		exampleResponseJSON := loadExampleResponse(t, "created_cart")
+		handlers := map[string]http.HandlerFunc{
+			"/v1/cart": generatePostHandler(t, expectedBody, exampleResponseJSON, http.StatusCreated),
+		}
+
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		expected := &dairyclient.Cart{
+			ID:            1,
+			UserID:        1,
+			LineItems:     []dairyclient.CartLineItem{},
+			DiscountCodes: []string{},
+			CreatedOn:     buildTestTime(t),
+		}
+
+		actual, err := c.CreateCart(exampleInput)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("with bad server response", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/cart": generatePostHandler(t, expectedBody, exampleBadJSON, http.StatusCreated),
+		}
+
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		_, err := c.CreateCart(exampleInput)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestGetCart(t *testing.T) {
+	existentID, nonexistentID := uint64(1), uint64(2)
+	exampleResponseJSON := loadExampleResponse(t, "cart")
+
+	handlers := map[string]http.HandlerFunc{
+		fmt.Sprintf("/v1/cart/%d", existentID):    generateGetHandler(t, exampleResponseJSON, http.StatusOK),
+		fmt.Sprintf("/v1/cart/%d", nonexistentID): generateGetHandler(t, buildNotFoundCartResponse(nonexistentID), http.StatusNotFound),
+	}
+
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("normal usage", func(*testing.T) {
+		expected := &dairyclient.Cart{
+			ID:     1,
+			UserID: 1,
+			LineItems: []dairyclient.CartLineItem{
+				{
+					ID:        1,
+					CartID:    1,
+					ProductID: 1,
+					SKU:       "t-shirt-small-red",
+					Quantity:  2,
+					UnitPrice: 20,
+					Total:     40,
+				},
+			},
+			DiscountCodes: []string{},
+			CreatedOn:     buildTestTime(t),
+		}
+
+		actual, err := c.GetCart(existentID)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("with nonexistent cart", func(*testing.T) {
+		_, err := c.GetCart(nonexistentID)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestAddCartItem(t *testing.T) {
+	existentID, nonexistentID := uint64(1), uint64(2)
+	exampleResponseJSON := loadExampleResponse(t, "cart")
+	expectedBody := `
+		{
+			"sku": "t-shirt-small-red",
+			"quantity": 2
+		}
+	`
+
+	handlers := map[string]http.HandlerFunc{
+		fmt.Sprintf("/v1/cart/%d/items", existentID):    generatePostHandler(t, expectedBody, exampleResponseJSON, http.StatusCreated),
+		fmt.Sprintf("/v1/cart/%d/items", nonexistentID): generatePostHandler(t, expectedBody, buildNotFoundCartResponse(nonexistentID), http.StatusNotFound),
+	}
+
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("normal usage", func(*testing.T) {
+		actual, err := c.AddCartItem(existentID, "t-shirt-small-red", 2)
+		assert.Nil(t, err)
+		assert.Len(t, actual.LineItems, 1)
+	})
+
+	t.Run("with nonexistent cart", func(*testing.T) {
+		_, err := c.AddCartItem(nonexistentID, "t-shirt-small-red", 2)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestUpdateCartItem(t *testing.T) {
+	existentID, nonexistentID := uint64(1), uint64(2)
+	exampleResponseJSON := loadExampleResponse(t, "updated_cart")
+	expectedBody := `
+		{
+			"quantity": 3
+		}
+	`
+
+	handlers := map[string]http.HandlerFunc{
+		fmt.Sprintf("/v1/cart/%d/item/%s", existentID, exampleSKU):    generatePatchHandler(t, expectedBody, exampleResponseJSON, http.StatusOK),
+		fmt.Sprintf("/v1/cart/%d/item/%s", nonexistentID, exampleSKU): generatePatchHandler(t, expectedBody, buildNotFoundCartResponse(nonexistentID), http.StatusNotFound),
+	}
+
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("normal usage", func(*testing.T) {
+		actual, err := c.UpdateCartItem(existentID, exampleSKU, 3)
+		assert.Nil(t, err)
+		assert.Equal(t, uint32(3), actual.LineItems[0].Quantity)
+	})
+
+	t.Run("with nonexistent cart", func(*testing.T) {
+		_, err := c.UpdateCartItem(nonexistentID, exampleSKU, 3)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestRemoveCartItem(t *testing.T) {
+	existentID, nonexistentID := uint64(1), uint64(2)
+
+	handlers := map[string]http.HandlerFunc{
+		fmt.Sprintf("/v1/cart/%d/item/%s", existentID, exampleSKU):    generateDeleteHandler(t, "{}", http.StatusOK),
+		fmt.Sprintf("/v1/cart/%d/item/%s", nonexistentID, exampleSKU): generateDeleteHandler(t, buildNotFoundCartResponse(nonexistentID), http.StatusNotFound),
+	}
+
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("normal usage", func(*testing.T) {
+		err := c.RemoveCartItem(existentID, exampleSKU)
+		assert.Nil(t, err)
+	})
+
+	t.Run("with nonexistent cart", func(*testing.T) {
+		err := c.RemoveCartItem(nonexistentID, exampleSKU)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestApplyDiscountCode(t *testing.T) {
+	existentID, nonexistentID := uint64(1), uint64(2)
+	exampleResponseJSON := loadExampleResponse(t, "updated_cart")
+	expectedBody := `
+		{
+			"code": "TENOFF"
+		}
+	`
+
+	handlers := map[string]http.HandlerFunc{
+		fmt.Sprintf("/v1/cart/%d/discount", existentID):    generatePostHandler(t, expectedBody, exampleResponseJSON, http.StatusOK),
+		fmt.Sprintf("/v1/cart/%d/discount", nonexistentID): generatePostHandler(t, expectedBody, buildNotFoundCartResponse(nonexistentID), http.StatusNotFound),
+	}
+
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("normal usage", func(*testing.T) {
+		actual, err := c.ApplyDiscountCode(existentID, "TENOFF")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"TENOFF"}, actual.DiscountCodes)
+	})
+
+	t.Run("with nonexistent cart", func(*testing.T) {
+		_, err := c.ApplyDiscountCode(nonexistentID, "TENOFF")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestGetCartTotals(t *testing.T) {
+	existentID, nonexistentID := uint64(1), uint64(2)
+	exampleResponseJSON := loadExampleResponse(t, "cart_totals")
+
+	handlers := map[string]http.HandlerFunc{
+		fmt.Sprintf("/v1/cart/%d/totals", existentID):    generateGetHandler(t, exampleResponseJSON, http.StatusOK),
+		fmt.Sprintf("/v1/cart/%d/totals", nonexistentID): generateGetHandler(t, buildNotFoundCartResponse(nonexistentID), http.StatusNotFound),
+	}
+
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("normal usage", func(*testing.T) {
+		expected := &dairyclient.CartTotals{
+			ItemCount: 3,
+			Subtotal:  60,
+			Discount:  6,
+			Tax:       4.32,
+			Total:     58.32,
+		}
+
+		actual, err := c.GetCartTotals(existentID)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("with nonexistent cart", func(*testing.T) {
+		_, err := c.GetCartTotals(nonexistentID)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,286 @@
+// Package changefeed turns periodic polling of a Dairycart store's list endpoints into a stream of typed catalog
+// change events, checkpointing its progress so that a restarted watcher resumes where the last one stopped.
+//
+// The API has no filter for archival, and list responses leave out product options, so every poll reads the
+// complete active catalog and each product root. Creations and updates are found by timestamp, and archivals by
+// noticing an entity has dropped out of the active listing. This makes a poll cost about as much as a full read
+// of the catalog, so the polling interval should be set with that in mind.
+package changefeed
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/dairycart/dairymodels/v1"
+)
+
+// EntityType identifies the kind of catalog entity an Event describes
+type EntityType string
+
+// These are the entity types a Watcher reports on
+const (
+	ProductEntity       EntityType = "product"
+	ProductRootEntity   EntityType = "product_root"
+	ProductOptionEntity EntityType = "product_option"
+	DiscountEntity      EntityType = "discount"
+)
+
+// EventType identifies what happened to an entity
+type EventType string
+
+// These are the kinds of change a Watcher reports
+const (
+	Created  EventType = "created"
+	Updated  EventType = "updated"
+	Archived EventType = "archived"
+)
+
+// DefaultMinBackoff is how long a Watcher first waits before retrying a failed poll
+const DefaultMinBackoff = time.Second
+
+// Event describes a single change to a catalog entity. For created and updated entities, exactly one of the
+// entity fields is set, according to Entity. Archived entities are no longer listed, so their events carry only
+// the ID, and At is when the archival was noticed.
+type Event struct {
+	Type   EventType
+	Entity EntityType
+	ID     uint64
+	At     time.Time
+
+	Product       *models.Product
+	ProductRoot   *models.ProductRoot
+	ProductOption *models.ProductOption
+	Discount      *models.Discount
+}
+
+// Source is the subset of the client a Watcher polls
+type Source interface {
+	GetAllProducts() ([]models.Product, error)
+	GetAllProductRoots() ([]models.ProductRoot, error)
+	GetProductRoot(rootID uint64) (*models.ProductRoot, error)
+	GetAllDiscounts() ([]models.Discount, error)
+}
+
+// Watcher polls a Source for catalog changes and emits them as Events
+type Watcher struct {
+	source       Source
+	checkpointer Checkpointer
+	interval     time.Duration
+	events       chan Event
+
+	// MinBackoff is how long to wait before the first retry of a failed poll. It defaults to DefaultMinBackoff.
+	MinBackoff time.Duration
+	// OnError, if set, is called with every failed poll before it is retried
+	OnError func(error)
+
+	cursor Cursor
+}
+
+// NewWatcher builds a Watcher that polls src every interval, persisting its progress with cp
+func NewWatcher(src Source, cp Checkpointer, interval time.Duration) *Watcher {
+	return &Watcher{
+		source:       src,
+		checkpointer: cp,
+		interval:     interval,
+		events:       make(chan Event),
+		MinBackoff:   DefaultMinBackoff,
+	}
+}
+
+// Events returns the channel changes are delivered on. It is closed when Run returns.
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// Run loads the last checkpoint and polls until ctx is cancelled. A failed poll is passed to OnError, if it is set,
+// and retried after a backoff that starts at MinBackoff and doubles up to the polling interval. A retry resumes from
+// the last checkpoint saved.
+func (w *Watcher) Run(ctx context.Context) error {
+	defer close(w.events)
+
+	cursor, err := w.checkpointer.Load()
+	if err != nil {
+		return err
+	}
+	w.cursor = cursor
+
+	backoff := time.Duration(0)
+	for {
+		wait := w.interval
+		if err := w.poll(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if w.OnError != nil {
+				w.OnError(err)
+			}
+			backoff = nextBackoff(backoff, w.MinBackoff, w.interval)
+			wait = backoff
+		} else {
+			backoff = 0
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// nextBackoff doubles the previous backoff, starting at min and never exceeding max
+func nextBackoff(previous, min, max time.Duration) time.Duration {
+	next := previous * 2
+	if next < min {
+		next = min
+	}
+	if next > max {
+		next = max
+	}
+	return next
+}
+
+// step is one entity type's share of a poll: the events found, and the IDs now active
+type step struct {
+	entity EntityType
+	events []Event
+	active map[uint64]bool
+}
+
+func (w *Watcher) poll(ctx context.Context) error {
+	products, err := w.source.GetAllProducts()
+	if err != nil {
+		return err
+	}
+	if err := w.emit(ctx, w.productStep(products, time.Now())); err != nil {
+		return err
+	}
+
+	steps, err := w.productRootSteps(time.Now())
+	if err != nil {
+		return err
+	}
+	for _, s := range steps {
+		if err := w.emit(ctx, s); err != nil {
+			return err
+		}
+	}
+
+	discounts, err := w.source.GetAllDiscounts()
+	if err != nil {
+		return err
+	}
+	return w.emit(ctx, w.discountStep(discounts, time.Now()))
+}
+
+// emit delivers a step's events in the order they happened, then records the step's active IDs and saves the
+// cursor. Archived events don't advance the cursor, since their time is when the archival was noticed.
+func (w *Watcher) emit(ctx context.Context, s step) error {
+	sort.SliceStable(s.events, func(i, j int) bool {
+		return s.events[i].At.Before(s.events[j].At)
+	})
+
+	for _, e := range s.events {
+		select {
+		case w.events <- e:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if e.Type != Archived {
+			w.cursor.advance(e.Entity, e.At)
+		}
+	}
+
+	if len(s.events) == 0 && sameIDs(w.cursor.Active[s.entity], s.active) {
+		return nil
+	}
+	w.cursor.setActive(s.entity, s.active)
+	return w.checkpointer.Save(w.cursor)
+}
+
+func sameIDs(a, b map[uint64]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for id := range a {
+		if !b[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// archivedEvents builds the events for IDs that have dropped out of the active listing
+func archivedEvents(entity EntityType, ids []uint64, noticed time.Time) []Event {
+	events := make([]Event, 0, len(ids))
+	for _, id := range ids {
+		events = append(events, Event{Type: Archived, Entity: entity, ID: id, At: noticed})
+	}
+	return events
+}
+
+func (w *Watcher) productStep(products []models.Product, noticed time.Time) step {
+	d := Detect(w.cursor.Products, w.cursor.Active[ProductEntity], products, ProductStamp)
+	events := archivedEvents(ProductEntity, d.Archived, noticed)
+	for _, c := range d.Changed {
+		p := c.Entity
+		events = append(events, Event{Type: c.Type, Entity: ProductEntity, ID: p.ID, At: c.At, Product: &p})
+	}
+	return step{entity: ProductEntity, events: events, active: d.Active}
+}
+
+// productRootSteps reads every product root on its own, since list responses leave out their options, and
+// returns a step for the roots followed by one for their options
+func (w *Watcher) productRootSteps(noticed time.Time) ([]step, error) {
+	listed, err := w.source.GetAllProductRoots()
+	if err != nil {
+		return nil, err
+	}
+
+	roots := make([]models.ProductRoot, 0, len(listed))
+	var options []models.ProductOption
+	for _, l := range listed {
+		r, err := w.source.GetProductRoot(l.ID)
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, *r)
+		for _, o := range r.Options {
+			if o.ArchivedOn == nil {
+				options = append(options, o)
+			}
+		}
+	}
+
+	rd := Detect(w.cursor.ProductRoots, w.cursor.Active[ProductRootEntity], roots, ProductRootStamp)
+	rootEvents := archivedEvents(ProductRootEntity, rd.Archived, noticed)
+	for _, c := range rd.Changed {
+		r := c.Entity
+		rootEvents = append(rootEvents, Event{Type: c.Type, Entity: ProductRootEntity, ID: r.ID, At: c.At, ProductRoot: &r})
+	}
+
+	od := Detect(w.cursor.ProductOptions, w.cursor.Active[ProductOptionEntity], options, ProductOptionStamp)
+	optionEvents := archivedEvents(ProductOptionEntity, od.Archived, noticed)
+	for _, c := range od.Changed {
+		o := c.Entity
+		optionEvents = append(optionEvents, Event{Type: c.Type, Entity: ProductOptionEntity, ID: o.ID, At: c.At, ProductOption: &o})
+	}
+
+	return []step{
+		{entity: ProductRootEntity, events: rootEvents, active: rd.Active},
+		{entity: ProductOptionEntity, events: optionEvents, active: od.Active},
+	}, nil
+}
+
+func (w *Watcher) discountStep(discounts []models.Discount, noticed time.Time) step {
+	d := Detect(w.cursor.Discounts, w.cursor.Active[DiscountEntity], discounts, DiscountStamp)
+	events := archivedEvents(DiscountEntity, d.Archived, noticed)
+	for _, c := range d.Changed {
+		disc := c.Entity
+		events = append(events, Event{Type: c.Type, Entity: DiscountEntity, ID: disc.ID, At: c.At, Discount: &disc})
+	}
+	return step{entity: DiscountEntity, events: events, active: d.Active}
+}
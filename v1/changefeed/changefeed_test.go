@@ -0,0 +1,370 @@
+package changefeed_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dairycart/dairyclient/v1"
+	"github.com/dairycart/dairyclient/v1/changefeed"
+	"github.com/dairycart/dairymodels/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ changefeed.Source = (*dairyclient.V1Client)(nil)
+
+var baseTime = time.Date(2017, 12, 10, 15, 58, 43, 0, time.UTC)
+
+func at(minutes int) time.Time {
+	return baseTime.Add(time.Duration(minutes) * time.Minute)
+}
+
+func dt(minutes int) *models.Dairytime {
+	return &models.Dairytime{Time: at(minutes)}
+}
+
+// fakeSource serves its entities the way the store does: listings leave out archived entities, and only a
+// single product root is returned with its options
+type fakeSource struct {
+	products  []models.Product
+	roots     []models.ProductRoot
+	discounts []models.Discount
+	fetched   []uint64
+
+	// failures is how many product listings fail before the source recovers
+	mu       sync.Mutex
+	failures int
+}
+
+func (fs *fakeSource) GetAllProducts() ([]models.Product, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if fs.failures > 0 {
+		fs.failures--
+		return nil, errors.New("arbitrary error")
+	}
+	var out []models.Product
+	for _, p := range fs.products {
+		if p.ArchivedOn == nil {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (fs *fakeSource) GetAllProductRoots() ([]models.ProductRoot, error) {
+	var out []models.ProductRoot
+	for _, r := range fs.roots {
+		if r.ArchivedOn == nil {
+			r.Options = nil
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (fs *fakeSource) GetProductRoot(rootID uint64) (*models.ProductRoot, error) {
+	fs.fetched = append(fs.fetched, rootID)
+	for _, r := range fs.roots {
+		if r.ID == rootID {
+			return &r, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (fs *fakeSource) GetAllDiscounts() ([]models.Discount, error) {
+	var out []models.Discount
+	for _, d := range fs.discounts {
+		if d.ArchivedOn == nil {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+func collect(t *testing.T, w *changefeed.Watcher, cancel context.CancelFunc, n int) []changefeed.Event {
+	t.Helper()
+	var events []changefeed.Event
+	timeout := time.After(time.Second)
+	for len(events) < n {
+		select {
+		case e := <-w.Events():
+			events = append(events, e)
+		case <-timeout:
+			require.FailNow(t, "timed out waiting for events", "got %d of %d", len(events), n)
+		}
+	}
+	cancel()
+	return events
+}
+
+func TestWatcher(t *testing.T) {
+	t.Run("emits events in order and checkpoints", func(*testing.T) {
+		src := &fakeSource{
+			products: []models.Product{
+				{ID: 1, SKU: "created", CreatedOn: at(3)},
+				{ID: 2, SKU: "updated", CreatedOn: at(-10), UpdatedOn: dt(2)},
+				{ID: 3, SKU: "archived", CreatedOn: at(-10), ArchivedOn: dt(1)},
+				{ID: 4, SKU: "stale", CreatedOn: at(-10), UpdatedOn: dt(-5)},
+			},
+			roots: []models.ProductRoot{
+				{
+					ID:        1,
+					CreatedOn: at(4),
+					Options:   []models.ProductOption{{ID: 7, CreatedOn: at(4)}},
+				},
+			},
+			discounts: []models.Discount{
+				{ID: 9, CreatedOn: at(-10), UpdatedOn: dt(5)},
+			},
+		}
+		cp := &changefeed.MemoryCheckpointer{}
+		require.Nil(t, cp.Save(changefeed.Cursor{
+			Products:       baseTime,
+			ProductRoots:   baseTime,
+			Discounts:      baseTime,
+			ProductOptions: baseTime,
+			Active: map[changefeed.EntityType]map[uint64]bool{
+				changefeed.ProductEntity: {2: true, 3: true, 4: true},
+			},
+		}))
+
+		w := changefeed.NewWatcher(src, cp, time.Hour)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- w.Run(ctx) }()
+
+		events := collect(t, w, cancel, 6)
+		assert.Equal(t, context.Canceled, <-done)
+
+		type summary struct {
+			Type   changefeed.EventType
+			Entity changefeed.EntityType
+			ID     uint64
+		}
+		var actual []summary
+		for _, e := range events {
+			actual = append(actual, summary{e.Type, e.Entity, e.ID})
+		}
+		expected := []summary{
+			{changefeed.Updated, changefeed.ProductEntity, 2},
+			{changefeed.Created, changefeed.ProductEntity, 1},
+			{changefeed.Archived, changefeed.ProductEntity, 3},
+			{changefeed.Created, changefeed.ProductRootEntity, 1},
+			{changefeed.Created, changefeed.ProductOptionEntity, 7},
+			{changefeed.Updated, changefeed.DiscountEntity, 9},
+		}
+		assert.Equal(t, expected, actual)
+		assert.Equal(t, "created", events[1].Product.SKU)
+		assert.Nil(t, events[2].Product)
+
+		cursor, err := cp.Load()
+		require.Nil(t, err)
+		assert.Equal(t, at(3), cursor.Products)
+		assert.Equal(t, at(4), cursor.ProductRoots)
+		assert.Equal(t, at(4), cursor.ProductOptions)
+		assert.Equal(t, at(5), cursor.Discounts)
+		assert.Equal(t, map[uint64]bool{1: true, 2: true, 4: true}, cursor.Active[changefeed.ProductEntity])
+		assert.Equal(t, map[uint64]bool{7: true}, cursor.Active[changefeed.ProductOptionEntity])
+	})
+
+	t.Run("notices an option change on an unchanged root", func(*testing.T) {
+		src := &fakeSource{
+			roots: []models.ProductRoot{
+				{
+					ID:        1,
+					CreatedOn: at(-10),
+					Options:   []models.ProductOption{{ID: 7, CreatedOn: at(-10), UpdatedOn: dt(2)}},
+				},
+			},
+		}
+		cp := &changefeed.MemoryCheckpointer{}
+		require.Nil(t, cp.Save(changefeed.Cursor{ProductRoots: baseTime, ProductOptions: baseTime}))
+
+		w := changefeed.NewWatcher(src, cp, time.Hour)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- w.Run(ctx) }()
+
+		events := collect(t, w, cancel, 1)
+		<-done
+		assert.Equal(t, changefeed.Updated, events[0].Type)
+		assert.Equal(t, changefeed.ProductOptionEntity, events[0].Entity)
+		assert.Equal(t, uint64(7), events[0].ProductOption.ID)
+		assert.Equal(t, []uint64{1}, src.fetched)
+	})
+
+	t.Run("notices an archival by its disappearance", func(*testing.T) {
+		src := &fakeSource{
+			discounts: []models.Discount{
+				{ID: 1, CreatedOn: at(-10)},
+				{ID: 2, CreatedOn: at(-10), ArchivedOn: dt(1)},
+			},
+		}
+		cp := &changefeed.MemoryCheckpointer{}
+		require.Nil(t, cp.Save(changefeed.Cursor{
+			Discounts: baseTime,
+			Active: map[changefeed.EntityType]map[uint64]bool{
+				changefeed.DiscountEntity: {1: true, 2: true},
+			},
+		}))
+
+		w := changefeed.NewWatcher(src, cp, time.Hour)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- w.Run(ctx) }()
+
+		events := collect(t, w, cancel, 1)
+		<-done
+		assert.Equal(t, changefeed.Archived, events[0].Type)
+		assert.Equal(t, uint64(2), events[0].ID)
+		assert.Nil(t, events[0].Discount)
+
+		cursor, err := cp.Load()
+		require.Nil(t, err)
+		assert.Equal(t, baseTime, cursor.Discounts, "an archival should not move the cursor")
+		assert.Equal(t, map[uint64]bool{1: true}, cursor.Active[changefeed.DiscountEntity])
+	})
+
+	t.Run("resumes from checkpoint", func(*testing.T) {
+		src := &fakeSource{
+			products: []models.Product{
+				{ID: 1, CreatedOn: at(1)},
+				{ID: 2, CreatedOn: at(2)},
+			},
+		}
+		cp := &changefeed.MemoryCheckpointer{}
+		require.Nil(t, cp.Save(changefeed.Cursor{Products: at(1)}))
+
+		w := changefeed.NewWatcher(src, cp, time.Hour)
+		ctx, cancel := context.WithCancel(context.Background())
+		go w.Run(ctx)
+
+		events := collect(t, w, cancel, 1)
+		assert.Equal(t, uint64(2), events[0].ID)
+	})
+
+	t.Run("replays the catalog without a checkpoint", func(*testing.T) {
+		src := &fakeSource{
+			products: []models.Product{
+				{ID: 1, CreatedOn: at(1)},
+				{ID: 2, CreatedOn: at(-10), ArchivedOn: dt(2)},
+			},
+		}
+
+		w := changefeed.NewWatcher(src, &changefeed.MemoryCheckpointer{}, time.Hour)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- w.Run(ctx) }()
+
+		events := collect(t, w, cancel, 1)
+		<-done
+		assert.Equal(t, changefeed.Created, events[0].Type)
+		assert.Equal(t, uint64(1), events[0].ID)
+	})
+
+	t.Run("retries after source error", func(*testing.T) {
+		src := &fakeSource{
+			products: []models.Product{{ID: 1, CreatedOn: at(1)}},
+			failures: 2,
+		}
+
+		var errs []error
+		w := changefeed.NewWatcher(src, &changefeed.MemoryCheckpointer{}, time.Hour)
+		w.MinBackoff = time.Millisecond
+		w.OnError = func(err error) { errs = append(errs, err) }
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- w.Run(ctx) }()
+
+		events := collect(t, w, cancel, 1)
+		assert.Equal(t, uint64(1), events[0].ID)
+		assert.Equal(t, context.Canceled, <-done)
+		assert.Len(t, errs, 2)
+		_, open := <-w.Events()
+		assert.False(t, open, "the events channel should be closed when Run returns")
+	})
+}
+
+func TestDetect(t *testing.T) {
+	listed := []models.Product{
+		{ID: 1, CreatedOn: at(1)},
+		{ID: 2, CreatedOn: at(-10), UpdatedOn: dt(2)},
+		{ID: 3, CreatedOn: at(-10)},
+	}
+
+	t.Run("with known IDs", func(*testing.T) {
+		known := map[uint64]bool{2: true, 3: true, 5: true, 4: true}
+		d := changefeed.Detect(baseTime, known, listed, changefeed.ProductStamp)
+
+		require.Len(t, d.Changed, 2)
+		assert.Equal(t, changefeed.Created, d.Changed[0].Type)
+		assert.Equal(t, uint64(1), d.Changed[0].Entity.ID)
+		assert.Equal(t, changefeed.Updated, d.Changed[1].Type)
+		assert.Equal(t, at(2), d.Changed[1].At)
+		assert.Equal(t, []uint64{4, 5}, d.Archived)
+		assert.Equal(t, map[uint64]bool{1: true, 2: true, 3: true}, d.Active)
+	})
+
+	t.Run("without known IDs", func(*testing.T) {
+		d := changefeed.Detect(time.Time{}, nil, listed, changefeed.ProductStamp)
+		assert.Len(t, d.Changed, 3)
+		assert.Empty(t, d.Archived)
+	})
+}
+
+func TestClassify(t *testing.T) {
+	example := []struct {
+		name       string
+		createdOn  time.Time
+		updatedOn  *models.Dairytime
+		archivedOn *models.Dairytime
+		expected   changefeed.EventType
+		ok         bool
+	}{
+		{name: "created", createdOn: at(1), expected: changefeed.Created, ok: true},
+		{name: "created then updated", createdOn: at(1), updatedOn: dt(2), expected: changefeed.Created, ok: true},
+		{name: "updated", createdOn: at(-1), updatedOn: dt(2), expected: changefeed.Updated, ok: true},
+		{name: "archived", createdOn: at(-1), archivedOn: dt(2), expected: changefeed.Archived, ok: true},
+		{name: "archived before", createdOn: at(-2), updatedOn: dt(1), archivedOn: dt(-1)},
+		{name: "unchanged", createdOn: at(-2), updatedOn: dt(-1)},
+	}
+
+	for _, e := range example {
+		actual, _, ok := changefeed.Classify(baseTime, e.createdOn, e.updatedOn, e.archivedOn)
+		assert.Equal(t, e.ok, ok, e.name)
+		assert.Equal(t, e.expected, actual, e.name)
+	}
+}
+
+func TestFileCheckpointer(t *testing.T) {
+	fc := &changefeed.FileCheckpointer{Path: filepath.Join(t.TempDir(), "cursor.json")}
+
+	t.Run("without existing file", func(*testing.T) {
+		c, err := fc.Load()
+		assert.Nil(t, err)
+		assert.Equal(t, changefeed.Cursor{}, c)
+	})
+
+	t.Run("round trip", func(*testing.T) {
+		expected := changefeed.Cursor{Products: at(1), Discounts: at(2)}
+		require.Nil(t, fc.Save(expected))
+
+		actual, err := fc.Load()
+		assert.Nil(t, err)
+		assert.True(t, expected.Products.Equal(actual.Products))
+		assert.True(t, expected.Discounts.Equal(actual.Discounts))
+	})
+
+	t.Run("with unwritable directory", func(*testing.T) {
+		bad := &changefeed.FileCheckpointer{Path: filepath.Join(t.TempDir(), "nope", "cursor.json")}
+		assert.NotNil(t, bad.Save(changefeed.Cursor{}))
+	})
+}
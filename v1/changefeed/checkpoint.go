@@ -0,0 +1,107 @@
+package changefeed
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dairycart/dairyclient/v1/internal/atomicfile"
+)
+
+// Cursor records, per entity type, the time of the most recent change a Watcher has delivered and the IDs that
+// were active when it last polled
+type Cursor struct {
+	Products       time.Time `json:"products"`
+	ProductRoots   time.Time `json:"product_roots"`
+	ProductOptions time.Time `json:"product_options"`
+	Discounts      time.Time `json:"discounts"`
+
+	Active map[EntityType]map[uint64]bool `json:"active,omitempty"`
+}
+
+func (c *Cursor) setActive(entity EntityType, ids map[uint64]bool) {
+	active := make(map[EntityType]map[uint64]bool, len(c.Active)+1)
+	for e, known := range c.Active {
+		active[e] = known
+	}
+	active[entity] = ids
+	c.Active = active
+}
+
+func (c *Cursor) advance(entity EntityType, at time.Time) {
+	var field *time.Time
+	switch entity {
+	case ProductEntity:
+		field = &c.Products
+	case ProductRootEntity:
+		field = &c.ProductRoots
+	case ProductOptionEntity:
+		field = &c.ProductOptions
+	case DiscountEntity:
+		field = &c.Discounts
+	default:
+		return
+	}
+	if at.After(*field) {
+		*field = at
+	}
+}
+
+// Checkpointer persists a Watcher's Cursor between runs
+type Checkpointer interface {
+	Load() (Cursor, error)
+	Save(Cursor) error
+}
+
+// FileCheckpointer stores a Cursor as JSON in a file on disk
+type FileCheckpointer struct {
+	Path string
+}
+
+// Load reads the cursor from disk. A missing file yields a zero Cursor, which replays the entire catalog.
+func (fc *FileCheckpointer) Load() (Cursor, error) {
+	c := Cursor{}
+	data, err := ioutil.ReadFile(fc.Path)
+	if os.IsNotExist(err) {
+		return c, nil
+	} else if err != nil {
+		return c, err
+	}
+
+	err = json.Unmarshal(data, &c)
+	return c, err
+}
+
+// Save writes the cursor to the file using internal/atomicfile
+func (fc *FileCheckpointer) Save(c Cursor) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	return atomicfile.WriteFile(fc.Path, data)
+}
+
+// MemoryCheckpointer keeps a Cursor in memory, which is useful for tests and for watchers that should always
+// start from scratch
+type MemoryCheckpointer struct {
+	mu     sync.Mutex
+	cursor Cursor
+}
+
+// Load returns the last saved cursor
+func (mc *MemoryCheckpointer) Load() (Cursor, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	return mc.cursor, nil
+}
+
+// Save stores the cursor
+func (mc *MemoryCheckpointer) Save(c Cursor) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.cursor = c
+	return nil
+}
@@ -0,0 +1,96 @@
+package changefeed
+
+import (
+	"sort"
+	"time"
+
+	"github.com/dairycart/dairymodels/v1"
+)
+
+// Stamp is what Detect needs to know about an entity: its ID and timestamps
+type Stamp struct {
+	ID         uint64
+	CreatedOn  time.Time
+	UpdatedOn  *models.Dairytime
+	ArchivedOn *models.Dairytime
+}
+
+// ProductStamp returns a product's Stamp
+func ProductStamp(p models.Product) Stamp {
+	return Stamp{ID: p.ID, CreatedOn: p.CreatedOn, UpdatedOn: p.UpdatedOn, ArchivedOn: p.ArchivedOn}
+}
+
+// ProductRootStamp returns a product root's Stamp
+func ProductRootStamp(r models.ProductRoot) Stamp {
+	return Stamp{ID: r.ID, CreatedOn: r.CreatedOn, UpdatedOn: r.UpdatedOn, ArchivedOn: r.ArchivedOn}
+}
+
+// ProductOptionStamp returns a product option's Stamp
+func ProductOptionStamp(o models.ProductOption) Stamp {
+	return Stamp{ID: o.ID, CreatedOn: o.CreatedOn, UpdatedOn: o.UpdatedOn, ArchivedOn: o.ArchivedOn}
+}
+
+// DiscountStamp returns a discount's Stamp
+func DiscountStamp(d models.Discount) Stamp {
+	return Stamp{ID: d.ID, CreatedOn: d.CreatedOn, UpdatedOn: d.UpdatedOn, ArchivedOn: d.ArchivedOn}
+}
+
+// Change is a listed entity that was created or updated, and when
+type Change[T any] struct {
+	Type   EventType
+	At     time.Time
+	Entity T
+}
+
+// Detection is the result of comparing a listing of active entities with what was known about them
+type Detection[T any] struct {
+	// Changed holds the listed entities created or updated after the given time
+	Changed []Change[T]
+	// Archived holds, in ascending order, the known IDs missing from the listing
+	Archived []uint64
+	// Active holds every listed ID, to be passed as known next time
+	Active map[uint64]bool
+}
+
+// Detect compares a listing of every active entity with the IDs known to be active before it. Listed entities are
+// classified against since, and known IDs missing from the listing are reported as archived. A nil known reports
+// no archivals, which suits a first run.
+func Detect[T any](since time.Time, known map[uint64]bool, listed []T, stamp func(T) Stamp) Detection[T] {
+	d := Detection[T]{Active: make(map[uint64]bool, len(listed))}
+	for _, e := range listed {
+		s := stamp(e)
+		d.Active[s.ID] = true
+		if t, at, ok := Classify(since, s.CreatedOn, s.UpdatedOn, s.ArchivedOn); ok && t != Archived {
+			d.Changed = append(d.Changed, Change[T]{Type: t, At: at, Entity: e})
+		}
+	}
+
+	for id := range known {
+		if !d.Active[id] {
+			d.Archived = append(d.Archived, id)
+		}
+	}
+	sort.Slice(d.Archived, func(i, j int) bool { return d.Archived[i] < d.Archived[j] })
+
+	return d
+}
+
+// Classify decides whether an entity with the given timestamps changed after since, and if so how and when
+func Classify(since time.Time, createdOn time.Time, updatedOn, archivedOn *models.Dairytime) (EventType, time.Time, bool) {
+	if archivedOn != nil && archivedOn.Time.After(since) {
+		return Archived, archivedOn.Time, true
+	}
+	if archivedOn != nil {
+		return "", time.Time{}, false
+	}
+	if updatedOn != nil && updatedOn.Time.After(since) {
+		if createdOn.After(since) {
+			return Created, updatedOn.Time, true
+		}
+		return Updated, updatedOn.Time, true
+	}
+	if createdOn.After(since) {
+		return Created, createdOn, true
+	}
+	return "", time.Time{}, false
+}
@@ -1,7 +1,12 @@
 package dairyclient
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
@@ -16,6 +21,18 @@ type V1Client struct {
 	*http.Client
 	URL        *url.URL
 	AuthCookie *http.Cookie
+
+	// MaxResponseSize caps how many bytes of a response body the client will read. Zero means no limit.
+	MaxResponseSize int64
+
+	// CompressRequests gzips request bodies of at least MinCompressedRequestSize bytes, which is worth
+	// turning on for bulk creates and updates
+	CompressRequests bool
+
+	// SkipValidation turns off the client-side input checks (see Validate) that run before creates and updates
+	SkipValidation bool
+
+	compression CompressionStats
 }
 
 func NewV1Client(storeURL string, username string, password string, client *http.Client) (*V1Client, error) {
@@ -38,7 +55,7 @@ func NewV1Client(storeURL string, username string, password string, client *http
 		}
 	`, username, password))
 	req, _ := http.NewRequest(http.MethodPost, p, body)
-	res, err := dc.Do(req)
+	res, err := dc.Client.Do(req)
 	if err != nil {
 		return nil, errors.Wrap(err, "Error encountered logging into store")
 	}
@@ -77,12 +94,49 @@ func NewV1ClientFromCookie(apiURL string, cookie *http.Cookie, client *http.Clie
 
 func (dc *V1Client) executeRequest(req *http.Request) (*http.Response, error) {
 	req.AddCookie(dc.AuthCookie)
-	return dc.Do(req)
+	return dc.Client.Do(req)
+}
+
+// EmptyIdentifierError is returned, before any request is made, when a method is given an empty SKU or other
+// identifier that would otherwise vanish from the request path and send the request to a different endpoint
+type EmptyIdentifierError struct {
+	Name string
+}
+
+func (e *EmptyIdentifierError) Error() string {
+	return fmt.Sprintf("%s must not be empty", e.Name)
+}
+
+func requireIdentifier(name string, value string) error {
+	if value == "" {
+		return &EmptyIdentifierError{Name: name}
+	}
+	return nil
+}
+
+// escapePathSegment escapes a value so that it occupies exactly one path segment. Segments made up only of dots
+// are escaped too, since "." and ".." would otherwise be resolved away.
+func escapePathSegment(s string) string {
+	if s != "" && strings.Trim(s, ".") == "" {
+		return strings.Repeat("%2E", len(s))
+	}
+	return url.PathEscape(s)
+}
+
+// buildPath builds a relative URL of /v1 followed by each part as its own escaped path segment
+func buildPath(parts []string) *url.URL {
+	escaped := []string{"v1"}
+	for _, p := range parts {
+		escaped = append(escaped, escapePathSegment(p))
+	}
+
+	u := &url.URL{RawPath: strings.Join(escaped, "/")}
+	u.Path, _ = url.PathUnescape(u.RawPath)
+	return u
 }
 
 func (dc *V1Client) buildURL(queryParams map[string]string, parts ...string) string {
-	parts = append([]string{"v1"}, parts...)
-	u, _ := url.Parse(strings.Join(parts, "/"))
+	u := buildPath(parts)
 	queryString := mapToQueryValues(queryParams)
 	u.RawQuery = queryString.Encode()
 	return dc.URL.ResolveReference(u).String()
@@ -90,19 +144,15 @@ func (dc *V1Client) buildURL(queryParams map[string]string, parts ...string) str
 
 // BuildURL is the same as the unexported build URL, except I trust myself to never call the
 // unexported function with variables that could lead to an error being returned. This function
-// returns the error in the event a user needs to build an API url, but tries to do so with an
-// invalid value.
+// returns an *EmptyIdentifierError in the event a user tries to build an API url with an empty
+// path part.
 func (dc *V1Client) BuildURL(queryParams map[string]string, parts ...string) (string, error) {
-	parts = append([]string{"v1"}, parts...)
-
-	u, err := url.Parse(strings.Join(parts, "/"))
-	if err != nil {
-		return "", err
+	for _, p := range parts {
+		if err := requireIdentifier("path part", p); err != nil {
+			return "", err
+		}
 	}
-
-	queryString := mapToQueryValues(queryParams)
-	u.RawQuery = queryString.Encode()
-	return dc.URL.ResolveReference(u).String(), nil
+	return dc.buildURL(queryParams, parts...), nil
 }
 
 func (dc *V1Client) exists(uri string) (bool, error) {
@@ -115,68 +165,118 @@ func (dc *V1Client) exists(uri string) (bool, error) {
 	return res.StatusCode == http.StatusOK, nil
 }
 
-func (dc *V1Client) get(uri string, obj interface{}) *ClientError {
-	ce := &ClientError{}
+// send executes a request against the API, sending in as the JSON request body when it is not nil. Any
+// error it returns is a *ClientError.
+func (dc *V1Client) send(ctx context.Context, method string, uri string, headers map[string]string, in interface{}) (*http.Response, error) {
+	var body io.Reader
+	var compressed bool
+	if in != nil {
+		b, err := createBodyFromStruct(in)
+		if err != nil {
+			return nil, &ClientError{Err: errors.Wrap(err, "encountered error marshaling data to JSON")}
+		}
+		body, compressed, err = dc.compressBody(b)
+		if err != nil {
+			return nil, &ClientError{Err: errors.Wrap(err, "encountered error compressing request body")}
+		}
+	}
 
-	if err := interfaceArgIsNotPointerOrNil(obj); err != nil {
-		ce.Err = errors.Wrap(err, "struct to load must be a pointer")
-		return ce
+	req, err := http.NewRequestWithContext(ctx, method, uri, body)
+	if err != nil {
+		return nil, &ClientError{Err: errors.Wrap(err, "encountered error building request")}
 	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	// asking for gzip explicitly stops the transport from decompressing on our behalf, which lets us count
+	// the bytes it saves
+	req.Header.Set("Accept-Encoding", "gzip")
 
-	req, _ := http.NewRequest(http.MethodGet, uri, nil)
 	res, err := dc.executeRequest(req)
 	if err != nil {
-		ce.Err = errors.Wrap(err, "encountered error executing request")
-		return ce
+		return nil, &ClientError{Err: errors.Wrap(err, "encountered error executing request")}
+	}
+	if err := dc.decompressResponse(res); err != nil {
+		return nil, &ClientError{Err: err}
 	}
 
-	return unmarshalBody(res, &obj)
+	if dc.MaxResponseSize > 0 {
+		if res.ContentLength > dc.MaxResponseSize {
+			res.Body.Close()
+			return nil, &ClientError{Err: ErrResponseTooLarge}
+		}
+		res.Body = limitBody(res.Body, dc.MaxResponseSize)
+	}
+	return res, nil
 }
 
-func (dc *V1Client) delete(uri string) *ClientError {
-	req, _ := http.NewRequest(http.MethodDelete, uri, nil)
-	res, err := dc.executeRequest(req)
+// doJSON executes a request against the API via send and decodes a successful response into a new T. Any
+// error it returns is a *ClientError.
+func doJSON[T any](ctx context.Context, dc *V1Client, method string, uri string, headers map[string]string, in interface{}) (*T, error) {
+	res, err := dc.send(ctx, method, uri, headers, in)
 	if err != nil {
-		return &ClientError{Err: err}
+		return nil, err
 	}
 
-	return unmarshalBody(res, &models.ErrorResponse{})
+	out, ce := unmarshalBody[T](res)
+	if ce != nil {
+		return nil, ce
+	}
+	return out, nil
 }
 
-func (dc *V1Client) makeDataRequest(method string, uri string, in interface{}, out interface{}) *ClientError {
-	ce := &ClientError{}
+func get[T any](dc *V1Client, uri string) (*T, error) {
+	return doJSON[T](context.Background(), dc, http.MethodGet, uri, nil, nil)
+}
 
-	if err := interfaceArgIsNotPointerOrNil(out); err != nil {
-		ce.Err = errors.Wrap(err, "struct to load must be a pointer")
-		return ce
-	}
+func post[T any](dc *V1Client, uri string, in interface{}) (*T, error) {
+	return doJSON[T](context.Background(), dc, http.MethodPost, uri, nil, in)
+}
 
-	body, err := createBodyFromStruct(in)
+func patch[T any](dc *V1Client, uri string, in interface{}) (*T, error) {
+	return doJSON[T](context.Background(), dc, http.MethodPatch, uri, nil, in)
+}
+
+func (dc *V1Client) delete(uri string) error {
+	_, err := doJSON[models.ErrorResponse](context.Background(), dc, http.MethodDelete, uri, nil, nil)
+	return err
+}
+
+// Do calls an arbitrary /v1 endpoint through the authenticated client, for endpoints the client has no
+// method for yet. pathParts and query are turned into a URL the same way every other method builds one,
+// in is sent as the JSON request body when it is not nil, and a successful response is decoded into out
+// when out is not nil. Error responses from the API are returned as a *ClientError.
+//
+// This is a breaking change: Do shadows the Do method V1Client used to promote from its embedded *http.Client, so
+// code that called c.Do(req) with an *http.Request must now call c.Client.Do(req).
+func (dc *V1Client) Do(ctx context.Context, method string, pathParts []string, query map[string]string, in interface{}, out interface{}) error {
+	u, err := dc.BuildURL(query, pathParts...)
 	if err != nil {
-		ce.Err = errors.Wrap(err, "encountered error marshaling data to JSON")
-		return ce
+		return err
 	}
-
-	req, _ := http.NewRequest(method, uri, body)
-	res, err := dc.executeRequest(req)
+	res, err := dc.send(ctx, method, u, nil, in)
 	if err != nil {
-		ce.Err = errors.Wrap(err, "encountered error executing request")
-		return ce
+		return err
 	}
-
-	resErr := unmarshalBody(res, &out)
-	if resErr != nil {
-		ce.Err = errors.Wrap(err, "encountered error loading response from server")
+	if isErrorStatus(res.StatusCode) {
+		_, ce := unmarshalBody[models.ErrorResponse](res)
 		return ce
 	}
 
+	// read the raw bytes rather than decoding, so that an empty body counts as success rather than EOF
+	defer res.Body.Close()
+	raw, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return &ClientError{Err: errors.Wrap(err, "encountered error reading response")}
+	}
+	if out == nil || len(bytes.TrimSpace(raw)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return &ClientError{Err: errors.Wrap(err, "encountered error unmarshaling response")}
+	}
 	return nil
 }
-
-func (dc *V1Client) post(uri string, in interface{}, out interface{}) *ClientError {
-	return dc.makeDataRequest(http.MethodPost, uri, in, out)
-}
-
-func (dc *V1Client) patch(uri string, in interface{}, out interface{}) *ClientError {
-	return dc.makeDataRequest(http.MethodPatch, uri, in, out)
-}
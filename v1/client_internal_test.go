@@ -3,6 +3,7 @@
 package dairyclient
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -169,6 +170,10 @@ func TestExists(t *testing.T) {
 	})
 }
 
+type testThingsStruct struct {
+	Things string `json:"things"`
+}
+
 func TestGet(t *testing.T) {
 
 	var normalEndpointCalled bool
@@ -189,36 +194,11 @@ func TestGet(t *testing.T) {
 	defer ts.Close()
 	c := createInternalClient(t, ts)
 
-	t.Run("normal usage", func(*testing.T) {
-		expected := struct {
-			Things string `json:"things"`
-		}{
-			Things: "stuff",
-		}
-
-		actual := struct {
-			Things string `json:"things"`
-		}{}
-
-		err := c.get(c.buildURL(nil, "normal"), &actual)
-		assert.Nil(t, err)
-		assert.Equal(t, expected, actual, "actual struct should equal expected struct")
-		assert.True(t, normalEndpointCalled, "endpoint should have been called")
-	})
-
-	t.Run("nil input", func(t *testing.T) {
-		nilErr := c.get(c.buildURL(nil, "whatever"), nil)
-		assert.NotNil(t, nilErr)
-	})
-
-	t.Run("non pointer input", func(t *testing.T) {
-		actual := struct {
-			Things string `json:"things"`
-		}{}
-
-		ptrErr := c.get(c.buildURL(nil, "whatever"), actual)
-		assert.NotNil(t, ptrErr)
-	})
+	expected := &testThingsStruct{Things: "stuff"}
+	actual, err := get[testThingsStruct](c, c.buildURL(nil, "normal"))
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual, "actual struct should equal expected struct")
+	assert.True(t, normalEndpointCalled, "endpoint should have been called")
 }
 
 func TestDelete(t *testing.T) {
@@ -260,19 +240,23 @@ func TestDelete(t *testing.T) {
 	})
 }
 
-func TestMakeDataRequest(t *testing.T) {
+func TestDoJSON(t *testing.T) {
 	handlers := map[string]func(res http.ResponseWriter, req *http.Request){
 		"/v1/whatever": func(res http.ResponseWriter, req *http.Request) {
-			assert.Equal(t, req.Method, http.MethodPost, "makeDataRequest should only be making PUT or POST requests")
+			assert.Equal(t, req.Method, http.MethodPost, "doJSON should use the method it is given")
 			exampleResponse := `{"things":"stuff"}`
 
 			bodyBytes, err := ioutil.ReadAll(req.Body)
 			assert.Nil(t, err)
 			requestBody := string(bodyBytes)
-			assert.Equal(t, requestBody, exampleResponse, "makeDataRequest should attach the correct JSON to the request body")
+			assert.Equal(t, requestBody, exampleResponse, "doJSON should attach the correct JSON to the request body")
 
 			fmt.Fprintf(res, exampleResponse)
 		},
+		"/v1/headers": func(res http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, "value", req.Header.Get("X-Example"), "doJSON should attach the headers it is given")
+			fmt.Fprintf(res, `{"things":"stuff"}`)
+		},
 		"/v1/bad_json": func(res http.ResponseWriter, req *http.Request) {
 			fmt.Fprintf(res, exampleBadJSON)
 		},
@@ -282,66 +266,40 @@ func TestMakeDataRequest(t *testing.T) {
 	c := createInternalClient(t, ts)
 
 	t.Run("normal usage", func(*testing.T) {
-		expected := struct {
-			Things string `json:"things"`
-		}{
-			Things: "stuff",
-		}
-
-		actual := struct {
-			Things string `json:"things"`
-		}{}
+		expected := &testThingsStruct{Things: "stuff"}
 
-		err := c.makeDataRequest(http.MethodPost, c.buildURL(nil, "whatever"), expected, &actual)
+		actual, err := doJSON[testThingsStruct](context.Background(), c, http.MethodPost, c.buildURL(nil, "whatever"), nil, expected)
 		assert.Nil(t, err)
 		assert.Equal(t, expected, actual, "actual struct should equal expected struct")
 	})
 
-	t.Run("nil argument", func(*testing.T) {
-		ptrErr := c.makeDataRequest(http.MethodPost, c.buildURL(nil, "whatever"), struct{}{}, struct{}{})
-		assert.NotNil(t, ptrErr, "makeDataRequest should return an error when passed a non-pointer output param")
-	})
-
-	t.Run("non-pointer argument", func(*testing.T) {
-		nilErr := c.makeDataRequest(http.MethodPost, c.buildURL(nil, "whatever"), struct{}{}, nil)
-		assert.NotNil(t, nilErr, "makeDataRequest should return an error when passed a nil output param")
+	t.Run("with headers", func(*testing.T) {
+		headers := map[string]string{"X-Example": "value"}
+		_, err := doJSON[testThingsStruct](context.Background(), c, http.MethodGet, c.buildURL(nil, "headers"), headers, nil)
+		assert.Nil(t, err)
 	})
 
 	t.Run("invalid struct argument", func(*testing.T) {
 		f := &testBreakableStruct{Thing: "dongs"}
-		err := c.makeDataRequest(http.MethodPost, c.buildURL(nil, "whatever"), f, &struct{}{})
-		assert.NotNil(t, err, "makeDataRequest should return an error when passed an invalid input struct")
+		_, err := doJSON[testThingsStruct](context.Background(), c, http.MethodPost, c.buildURL(nil, "whatever"), nil, f)
+		assert.NotNil(t, err, "doJSON should return an error when passed an invalid input struct")
+		assert.IsType(t, &ClientError{}, err)
 	})
 
 	t.Run("unmarshal failure", func(*testing.T) {
-		expected := struct {
-			Things string `json:"things"`
-		}{
-			Things: "stuff",
-		}
-
-		actual := struct {
-			Things string `json:"things"`
-		}{}
-
-		err := c.makeDataRequest(http.MethodPost, c.buildURL(nil, "bad_json"), expected, &actual)
+		in := testThingsStruct{Things: "stuff"}
+		_, err := doJSON[testThingsStruct](context.Background(), c, http.MethodPost, c.buildURL(nil, "bad_json"), nil, in)
 		assert.NotNil(t, err)
+		assert.IsType(t, &ClientError{}, err)
 	})
 
 	t.Run("failed request", func(*testing.T) {
-		expected := struct {
-			Things string `json:"things"`
-		}{
-			Things: "stuff",
-		}
-
-		actual := struct {
-			Things string `json:"things"`
-		}{}
+		in := testThingsStruct{Things: "stuff"}
 
 		ts.Close()
-		err := c.makeDataRequest(http.MethodPost, c.buildURL(nil, "whatever"), expected, &actual)
-		assert.NotNil(t, err, "makeDataRequest should return an error when failing to execute request")
+		_, err := doJSON[testThingsStruct](context.Background(), c, http.MethodPost, c.buildURL(nil, "whatever"), nil, in)
+		assert.NotNil(t, err, "doJSON should return an error when failing to execute request")
+		assert.IsType(t, &ClientError{}, err)
 	})
 }
 
@@ -367,18 +325,9 @@ func TestPost(t *testing.T) {
 	defer ts.Close()
 	c := createInternalClient(t, ts)
 
-	expected := struct {
-		Things string `json:"things"`
-	}{
-		Things: "stuff",
-	}
-
-	actual := struct {
-		Things string `json:"things"`
-	}{}
+	expected := &testThingsStruct{Things: "stuff"}
 
-	exampleURI := c.buildURL(nil, "whatever")
-	err := c.post(exampleURI, expected, &actual)
+	actual, err := post[testThingsStruct](c, c.buildURL(nil, "whatever"), expected)
 	assert.Nil(t, err)
 	assert.Equal(t, expected, actual, "actual struct should equal expected struct")
 	assert.True(t, endpointCalled, "endpoint should have been called")
@@ -406,17 +355,9 @@ func TestPatch(t *testing.T) {
 	defer ts.Close()
 	c := createInternalClient(t, ts)
 
-	expected := struct {
-		Things string `json:"things"`
-	}{
-		Things: "stuff",
-	}
-
-	actual := struct {
-		Things string `json:"things"`
-	}{}
+	expected := &testThingsStruct{Things: "stuff"}
 
-	err := c.patch(c.buildURL(nil, "whatever"), expected, &actual)
+	actual, err := patch[testThingsStruct](c, c.buildURL(nil, "whatever"), expected)
 	assert.Nil(t, err)
 	assert.Equal(t, expected, actual, "actual struct should equal expected struct")
 	assert.True(t, endpointCalled, "endpoint should have been called")
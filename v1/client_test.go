@@ -1,7 +1,9 @@
 package dairyclient_test
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -9,6 +11,7 @@ import (
 	"github.com/dairycart/dairyclient/v1"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 ////////////////////////////////////////////////////////
@@ -86,10 +89,97 @@ func TestBuildURL(t *testing.T) {
 		assert.Equal(t, expected, actual, "BuildURL doesn't return the correct result. Expected `%s`, got `%s`", expected, actual)
 	})
 
-	t.Run("invalid URL", func(t *testing.T) {
-		actual, err := c.BuildURL(nil, `%gh&%ij`)
+	t.Run("with characters that need escaping", func(t *testing.T) {
+		expected := fmt.Sprintf("%s/v1/product/%%25gh&%%25ij%%2F%%3F%%23%%20", ts.URL)
+		actual, err := c.BuildURL(nil, "product", `%gh&%ij/?# `)
 
-		assert.NotNil(t, err)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("with dot segments", func(t *testing.T) {
+		expected := fmt.Sprintf("%s/v1/product/%%2E%%2E", ts.URL)
+		actual, err := c.BuildURL(nil, "product", "..")
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("with empty part", func(t *testing.T) {
+		actual, err := c.BuildURL(nil, "product", "")
+
+		assert.IsType(t, &dairyclient.EmptyIdentifierError{}, err)
 		assert.Empty(t, actual)
 	})
 }
+
+func TestDo(t *testing.T) {
+	type widget struct {
+		Name string `json:"name"`
+	}
+
+	handlers := map[string]http.HandlerFunc{
+		"/v1/widget": func(res http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, http.MethodPost, req.Method)
+			assert.Equal(t, "blue", req.URL.Query().Get("color"), "Do should attach the query it is given")
+			_, err := req.Cookie("dairycart")
+			assert.Nil(t, err, "Do should attach the auth cookie")
+
+			body, err := ioutil.ReadAll(req.Body)
+			assert.Nil(t, err)
+			assert.Equal(t, `{"name":"gizmo"}`, string(body))
+
+			fmt.Fprint(res, `{"name":"gizmo"}`)
+		},
+		"/v1/widget/1/ping": func(res http.ResponseWriter, req *http.Request) {
+			res.WriteHeader(http.StatusNoContent)
+		},
+		"/v1/widget/1/touch": func(res http.ResponseWriter, req *http.Request) {
+			res.WriteHeader(http.StatusOK)
+		},
+		"/v1/widget/2": func(res http.ResponseWriter, req *http.Request) {
+			res.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(res, `{"status":404,"message":"The widget you were looking for (id '2') does not exist"}`)
+		},
+	}
+
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("normal usage", func(*testing.T) {
+		actual := widget{}
+		err := c.Do(context.Background(), http.MethodPost, []string{"widget"}, map[string]string{"color": "blue"}, widget{Name: "gizmo"}, &actual)
+		assert.Nil(t, err)
+		assert.Equal(t, widget{Name: "gizmo"}, actual)
+	})
+
+	t.Run("without body or output", func(*testing.T) {
+		err := c.Do(context.Background(), http.MethodPost, []string{"widget", "1", "ping"}, nil, nil, nil)
+		assert.Nil(t, err)
+	})
+
+	t.Run("with empty successful response", func(*testing.T) {
+		actual := widget{}
+		err := c.Do(context.Background(), http.MethodPost, []string{"widget", "1", "touch"}, nil, nil, &actual)
+		assert.Nil(t, err, "an empty body with a 200 should not be treated as an error")
+		assert.Equal(t, widget{}, actual)
+	})
+
+	t.Run("with error response", func(*testing.T) {
+		err := c.Do(context.Background(), http.MethodGet, []string{"widget", "2"}, nil, nil, &widget{})
+		require.NotNil(t, err)
+		ce, ok := err.(*dairyclient.ClientError)
+		require.True(t, ok, "Do should return a *ClientError")
+		require.NotNil(t, ce.FromAPI)
+		assert.Equal(t, http.StatusNotFound, ce.FromAPI.Status)
+	})
+
+	t.Run("with cancelled context", func(*testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := c.Do(ctx, http.MethodPost, []string{"widget"}, nil, widget{Name: "gizmo"}, &widget{})
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,107 @@
+package dairyclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// MinCompressedRequestSize is the smallest request body the client will gzip when CompressRequests is set.
+// Smaller bodies tend to grow when compressed.
+const MinCompressedRequestSize = 1024
+
+// CompressionStats counts bytes before and after gzip for the requests and responses a client has compressed
+type CompressionStats struct {
+	RequestBytes            int64
+	CompressedRequestBytes  int64
+	ResponseBytes           int64
+	CompressedResponseBytes int64
+}
+
+// BytesSaved is how many fewer bytes went over the wire than would have without compression
+func (cs CompressionStats) BytesSaved() int64 {
+	return (cs.RequestBytes - cs.CompressedRequestBytes) + (cs.ResponseBytes - cs.CompressedResponseBytes)
+}
+
+// CompressionStats returns a snapshot of the client's compression counters
+func (dc *V1Client) CompressionStats() CompressionStats {
+	return CompressionStats{
+		RequestBytes:            atomic.LoadInt64(&dc.compression.RequestBytes),
+		CompressedRequestBytes:  atomic.LoadInt64(&dc.compression.CompressedRequestBytes),
+		ResponseBytes:           atomic.LoadInt64(&dc.compression.ResponseBytes),
+		CompressedResponseBytes: atomic.LoadInt64(&dc.compression.CompressedResponseBytes),
+	}
+}
+
+// compressBody gzips a request body if CompressRequests is set and the body is big enough to be worth it,
+// reporting whether it did so
+func (dc *V1Client) compressBody(body io.Reader) (io.Reader, bool, error) {
+	if !dc.CompressRequests {
+		return body, false, nil
+	}
+
+	raw, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(raw) < MinCompressedRequestSize {
+		return bytes.NewReader(raw), false, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return nil, false, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, false, err
+	}
+
+	atomic.AddInt64(&dc.compression.RequestBytes, int64(len(raw)))
+	atomic.AddInt64(&dc.compression.CompressedRequestBytes, int64(buf.Len()))
+	return &buf, true, nil
+}
+
+type countingReader struct {
+	r     io.Reader
+	count *int64
+}
+
+func (cr countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	atomic.AddInt64(cr.count, int64(n))
+	return n, err
+}
+
+type gzipBody struct {
+	io.Reader
+	io.Closer
+}
+
+// decompressResponse replaces a gzipped response body with one that yields the decompressed bytes, so callers
+// never see the encoding. Bytes are counted as they are read.
+func (dc *V1Client) decompressResponse(res *http.Response) error {
+	if res.Header.Get("Content-Encoding") != "gzip" {
+		return nil
+	}
+
+	zr, err := gzip.NewReader(countingReader{r: res.Body, count: &dc.compression.CompressedResponseBytes})
+	if err != nil {
+		res.Body.Close()
+		return errors.Wrap(err, "encountered error decompressing response")
+	}
+
+	res.Body = gzipBody{
+		Reader: countingReader{r: zr, count: &dc.compression.ResponseBytes},
+		Closer: res.Body,
+	}
+	res.Header.Del("Content-Encoding")
+	res.Header.Del("Content-Length")
+	res.ContentLength = -1
+	return nil
+}
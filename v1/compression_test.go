@@ -0,0 +1,122 @@
+package dairyclient_test
+
+import (
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gzipResponseHandler(t *testing.T, body string) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		t.Helper()
+		if req.Header.Get("Accept-Encoding") != "gzip" {
+			res.Write([]byte(body))
+			return
+		}
+
+		res.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(res)
+		_, err := zw.Write([]byte(body))
+		assert.Nil(t, err)
+		assert.Nil(t, zw.Close())
+	}
+}
+
+func TestResponseDecompression(t *testing.T) {
+	exampleGoodResponse := loadExampleResponse(t, "products")
+
+	handlers := map[string]http.HandlerFunc{
+		"/v1/products": gzipResponseHandler(t, exampleGoodResponse),
+		"/v1/broken": func(res http.ResponseWriter, req *http.Request) {
+			res.Header().Set("Content-Encoding", "gzip")
+			res.Write([]byte("not actually gzip"))
+		},
+	}
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("normal usage", func(*testing.T) {
+		products, err := c.GetProducts(nil)
+		require.Nil(t, err)
+		assert.Len(t, products, 5)
+
+		stats := c.CompressionStats()
+		assert.Equal(t, int64(len(exampleGoodResponse)), stats.ResponseBytes)
+		assert.True(t, stats.CompressedResponseBytes > 0)
+		assert.True(t, stats.BytesSaved() > 0, "compressing the product list should save bytes")
+	})
+
+	t.Run("with invalid gzip", func(*testing.T) {
+		err := c.Do(context.Background(), http.MethodGet, []string{"broken"}, nil, nil, nil)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestRequestCompression(t *testing.T) {
+	type bulk struct {
+		Padding string `json:"padding"`
+	}
+
+	var lastEncoding, lastBody string
+	handlers := map[string]http.HandlerFunc{
+		"/v1/bulk": func(res http.ResponseWriter, req *http.Request) {
+			lastEncoding = req.Header.Get("Content-Encoding")
+
+			var body = req.Body
+			if lastEncoding == "gzip" {
+				zr, err := gzip.NewReader(req.Body)
+				require.Nil(t, err)
+				body = zr
+			}
+			b, err := ioutil.ReadAll(body)
+			require.Nil(t, err)
+			lastBody = string(b)
+
+			res.WriteHeader(http.StatusNoContent)
+		},
+	}
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+
+	large := bulk{Padding: strings.Repeat("dairy", 1000)}
+	small := bulk{Padding: "dairy"}
+
+	t.Run("disabled by default", func(*testing.T) {
+		c := buildTestClient(t, ts)
+		err := c.Do(context.Background(), http.MethodPost, []string{"bulk"}, nil, large, nil)
+		require.Nil(t, err)
+		assert.Empty(t, lastEncoding)
+		assert.Equal(t, int64(0), c.CompressionStats().BytesSaved())
+	})
+
+	t.Run("with large body", func(*testing.T) {
+		c := buildTestClient(t, ts)
+		c.CompressRequests = true
+
+		err := c.Do(context.Background(), http.MethodPost, []string{"bulk"}, nil, large, nil)
+		require.Nil(t, err)
+		assert.Equal(t, "gzip", lastEncoding)
+		assert.Equal(t, `{"padding":"`+large.Padding+`"}`, lastBody)
+
+		stats := c.CompressionStats()
+		assert.Equal(t, int64(len(lastBody)), stats.RequestBytes)
+		assert.True(t, stats.CompressedRequestBytes < stats.RequestBytes)
+	})
+
+	t.Run("with small body", func(*testing.T) {
+		c := buildTestClient(t, ts)
+		c.CompressRequests = true
+
+		err := c.Do(context.Background(), http.MethodPost, []string{"bulk"}, nil, small, nil)
+		require.Nil(t, err)
+		assert.Empty(t, lastEncoding, "bodies under MinCompressedRequestSize should be sent as-is")
+	})
+}
@@ -0,0 +1,108 @@
+package dairyclient
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/dairycart/dairymodels/v1"
+
+	"github.com/pkg/errors"
+)
+
+// ErrUpdateDeclined is returned by UpdateProductWithPreview when the confirmation callback rejects the changes
+var ErrUpdateDeclined = errors.New("update was declined")
+
+// FieldChange describes one field an update would change. Field is the field's JSON name.
+type FieldChange struct {
+	Field string
+	From  interface{}
+	To    interface{}
+}
+
+func (fc FieldChange) String() string {
+	return fmt.Sprintf("%s: %v -> %v", fc.Field, fc.From, fc.To)
+}
+
+// Diff reports the fields of current that applying input would change. Zero values in a ProductUpdateInput are
+// left unchanged by the API, so they never show up as changes.
+func Diff(current *models.Product, input models.ProductUpdateInput) []FieldChange {
+	var changes []FieldChange
+	cv, iv := reflect.ValueOf(current).Elem(), reflect.ValueOf(input)
+	for i := 0; i < iv.NumField(); i++ {
+		f := iv.Type().Field(i)
+		to := iv.Field(i)
+		if to.IsZero() {
+			continue
+		}
+
+		from := cv.FieldByName(f.Name)
+		if !from.IsValid() {
+			continue
+		}
+		fromValue, toValue := diffValue(from), diffValue(to)
+		if sameValue(fromValue, toValue) {
+			continue
+		}
+
+		changes = append(changes, FieldChange{
+			Field: strings.Split(f.Tag.Get("json"), ",")[0],
+			From:  fromValue,
+			To:    toValue,
+		})
+	}
+	return changes
+}
+
+// diffValue unwraps Dairytimes, since inputs carry times as *models.Dairytime while products carry time.Time
+func diffValue(v reflect.Value) interface{} {
+	switch t := v.Interface().(type) {
+	case *models.Dairytime:
+		if t == nil {
+			return time.Time{}
+		}
+		return t.Time
+	case models.Dairytime:
+		return t.Time
+	}
+	return v.Interface()
+}
+
+// sameValue compares two field values, treating times in different locations as the same instant
+func sameValue(a, b interface{}) bool {
+	if at, ok := a.(time.Time); ok {
+		if bt, ok := b.(time.Time); ok {
+			return at.Equal(bt)
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// UpdateProductWithPreview fetches the current product, works out what the update would change, and, if confirm
+// is non-nil, only applies the update once confirm approves the changes. It returns the changes alongside the
+// updated product. When nothing would change, no update is made and the current product is returned.
+func (dc *V1Client) UpdateProductWithPreview(sku string, up models.ProductUpdateInput, confirm func([]FieldChange) bool) ([]FieldChange, *models.Product, error) {
+	if err := requireIdentifier("sku", sku); err != nil {
+		return nil, nil, err
+	}
+	if err := dc.validate(up); err != nil {
+		return nil, nil, err
+	}
+
+	current, err := dc.GetProduct(sku)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changes := Diff(current, up)
+	if len(changes) == 0 {
+		return changes, current, nil
+	}
+	if confirm != nil && !confirm(changes) {
+		return changes, nil, ErrUpdateDeclined
+	}
+
+	updated, err := dc.UpdateProduct(sku, up)
+	return changes, updated, err
+}
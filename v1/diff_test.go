@@ -0,0 +1,120 @@
+package dairyclient_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dairycart/dairyclient/v1"
+	"github.com/dairycart/dairymodels/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	availableOn := time.Date(2017, 12, 10, 15, 58, 43, 0, time.UTC)
+	current := &models.Product{
+		Name:        "T-Shirt",
+		SKU:         "t-shirt",
+		Quantity:    10,
+		Price:       20,
+		Taxable:     true,
+		AvailableOn: availableOn,
+	}
+
+	t.Run("with changes", func(*testing.T) {
+		input := models.ProductUpdateInput{
+			Name:     "Band T-Shirt",
+			Price:    20,
+			Quantity: 5,
+			Brand:    "Band",
+		}
+		expected := []dairyclient.FieldChange{
+			{Field: "name", From: "T-Shirt", To: "Band T-Shirt"},
+			{Field: "brand", From: "", To: "Band"},
+			{Field: "quantity", From: uint32(10), To: uint32(5)},
+		}
+		assert.Equal(t, expected, dairyclient.Diff(current, input))
+	})
+
+	t.Run("with unchanged available_on", func(*testing.T) {
+		input := models.ProductUpdateInput{AvailableOn: &models.Dairytime{Time: availableOn.In(time.FixedZone("CST", -6*60*60))}}
+		assert.Empty(t, dairyclient.Diff(current, input), "the same instant should not count as a change")
+	})
+
+	t.Run("with changed available_on", func(*testing.T) {
+		later := availableOn.Add(24 * time.Hour)
+		input := models.ProductUpdateInput{AvailableOn: &models.Dairytime{Time: later}}
+		expected := []dairyclient.FieldChange{{Field: "available_on", From: availableOn, To: later}}
+		assert.Equal(t, expected, dairyclient.Diff(current, input))
+	})
+
+	t.Run("with zero values", func(*testing.T) {
+		assert.Empty(t, dairyclient.Diff(current, models.ProductUpdateInput{Taxable: false}))
+	})
+
+	t.Run("string form", func(*testing.T) {
+		fc := dairyclient.FieldChange{Field: "price", From: float32(20), To: float32(15)}
+		assert.Equal(t, "price: 20 -> 15", fc.String())
+	})
+}
+
+func TestUpdateProductWithPreview(t *testing.T) {
+	var updated bool
+	handlers := map[string]http.HandlerFunc{
+		"/v1/product/sku": func(res http.ResponseWriter, req *http.Request) {
+			if req.Method == http.MethodPatch {
+				updated = true
+				res.Write([]byte(loadExampleResponse(t, "updated_product")))
+				return
+			}
+			res.Write([]byte(loadExampleResponse(t, "product")))
+		},
+	}
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	input := models.ProductUpdateInput{Price: 15}
+
+	t.Run("normal usage", func(*testing.T) {
+		updated = false
+		var previewed []dairyclient.FieldChange
+		changes, product, err := c.UpdateProductWithPreview(exampleSKU, input, func(changes []dairyclient.FieldChange) bool {
+			previewed = changes
+			return true
+		})
+		require.Nil(t, err)
+		assert.Equal(t, []dairyclient.FieldChange{{Field: "price", From: float32(20), To: float32(15)}}, changes)
+		assert.Equal(t, changes, previewed)
+		assert.Equal(t, "name", product.Name)
+		assert.True(t, updated)
+	})
+
+	t.Run("without confirmation", func(*testing.T) {
+		updated = false
+		_, _, err := c.UpdateProductWithPreview(exampleSKU, input, nil)
+		assert.Nil(t, err)
+		assert.True(t, updated)
+	})
+
+	t.Run("when declined", func(*testing.T) {
+		updated = false
+		changes, product, err := c.UpdateProductWithPreview(exampleSKU, input, func([]dairyclient.FieldChange) bool { return false })
+		assert.Equal(t, dairyclient.ErrUpdateDeclined, err)
+		assert.Len(t, changes, 1)
+		assert.Nil(t, product)
+		assert.False(t, updated)
+	})
+
+	t.Run("with nothing to change", func(*testing.T) {
+		updated = false
+		changes, product, err := c.UpdateProductWithPreview(exampleSKU, models.ProductUpdateInput{Price: 20}, nil)
+		assert.Nil(t, err)
+		assert.Empty(t, changes)
+		assert.Equal(t, "sku", product.SKU)
+		assert.False(t, updated)
+	})
+}
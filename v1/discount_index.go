@@ -0,0 +1,128 @@
+package dairyclient
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dairycart/dairyclient/v1/pricing"
+	"github.com/dairycart/dairymodels/v1"
+)
+
+// DiscountIndex is an in-process cache of every discount keyed by code, so that checkout can resolve a code
+// without a round trip. It must be populated with Refresh or kept current with Start.
+type DiscountIndex struct {
+	client *V1Client
+
+	mu          sync.RWMutex
+	byCode      map[string]models.Discount
+	refreshedOn time.Time
+
+	runMu sync.Mutex
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// NewDiscountIndex builds an empty DiscountIndex backed by a given client
+func NewDiscountIndex(dc *V1Client) *DiscountIndex {
+	return &DiscountIndex{
+		client: dc,
+		byCode: map[string]models.Discount{},
+	}
+}
+
+func normalizeDiscountCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
+
+// Refresh replaces the contents of the index with the discounts currently in the store
+func (di *DiscountIndex) Refresh() error {
+	discounts, err := di.client.GetAllDiscounts()
+	if err != nil {
+		return err
+	}
+
+	byCode := make(map[string]models.Discount, len(discounts))
+	for _, d := range discounts {
+		if d.Code == "" {
+			continue
+		}
+		byCode[normalizeDiscountCode(d.Code)] = d
+	}
+
+	di.mu.Lock()
+	di.byCode = byCode
+	di.refreshedOn = time.Now()
+	di.mu.Unlock()
+	return nil
+}
+
+// RefreshedOn returns the time of the last successful refresh
+func (di *DiscountIndex) RefreshedOn() time.Time {
+	di.mu.RLock()
+	defer di.mu.RUnlock()
+	return di.refreshedOn
+}
+
+// Start refreshes the index right away and then every interval until Stop is called. Refresh errors are passed
+// to onError, if it is not nil, and otherwise leave the previous contents of the index in place. Calling Start
+// again before Stop does nothing.
+func (di *DiscountIndex) Start(interval time.Duration, onError func(error)) {
+	di.runMu.Lock()
+	defer di.runMu.Unlock()
+	if di.stop != nil {
+		return
+	}
+	di.stop = make(chan struct{})
+	di.done = make(chan struct{})
+
+	if err := di.Refresh(); err != nil && onError != nil {
+		onError(err)
+	}
+
+	go func(stop <-chan struct{}, done chan<- struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := di.Refresh(); err != nil && onError != nil {
+					onError(err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}(di.stop, di.done)
+}
+
+// Stop halts the periodic refresh started by Start and waits for it to finish
+func (di *DiscountIndex) Stop() {
+	di.runMu.Lock()
+	defer di.runMu.Unlock()
+	if di.stop == nil {
+		return
+	}
+	close(di.stop)
+	<-di.done
+	di.stop = nil
+}
+
+// Lookup resolves a discount code, ignoring case. It returns ErrDiscountNotFound for unknown codes, and a
+// *pricing.IneligibilityError alongside the discount when the discount is not usable at the given time, so
+// callers can tell an expired or exhausted code apart from a mistyped one.
+func (di *DiscountIndex) Lookup(code string, at time.Time) (*models.Discount, error) {
+	di.mu.RLock()
+	d, ok := di.byCode[normalizeDiscountCode(code)]
+	di.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrDiscountNotFound
+	}
+	if err := pricing.CheckActive(d, at); err != nil {
+		return &d, err
+	}
+	return &d, nil
+}
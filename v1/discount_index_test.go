@@ -0,0 +1,110 @@
+package dairyclient_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dairycart/dairyclient/v1"
+	"github.com/dairycart/dairyclient/v1/pricing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscountIndex(t *testing.T) {
+	handlers := map[string]http.HandlerFunc{
+		"/v1/discounts": generateGetHandler(t, buildCodedDiscountsResponse(t), http.StatusOK),
+	}
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	di := dairyclient.NewDiscountIndex(c)
+	require.Nil(t, di.Refresh())
+	assert.False(t, di.RefreshedOn().IsZero())
+
+	t.Run("with active code", func(*testing.T) {
+		d, err := di.Lookup("Active", time.Now())
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1), d.ID)
+	})
+
+	t.Run("with expired code", func(*testing.T) {
+		d, err := di.Lookup("expired", time.Now())
+		require.NotNil(t, err)
+		assert.Equal(t, uint64(2), d.ID)
+		assert.Equal(t, pricing.ReasonExpired, err.(*pricing.IneligibilityError).Reason)
+	})
+
+	t.Run("with exhausted code", func(*testing.T) {
+		_, err := di.Lookup(" EXHAUSTED ", time.Now())
+		require.NotNil(t, err)
+		assert.Equal(t, pricing.ReasonExhausted, err.(*pricing.IneligibilityError).Reason)
+	})
+
+	t.Run("with unknown code", func(*testing.T) {
+		d, err := di.Lookup("nope", time.Now())
+		assert.Nil(t, d)
+		assert.Equal(t, dairyclient.ErrDiscountNotFound, err)
+	})
+}
+
+func TestDiscountIndexStart(t *testing.T) {
+	var requests int32
+	handlers := map[string]http.HandlerFunc{
+		"/v1/discounts": func(res http.ResponseWriter, req *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			res.WriteHeader(http.StatusInternalServerError)
+			res.Write([]byte(`{"status":500,"message":"oh no"}`))
+		},
+	}
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	errs := make(chan error, 10)
+	di := dairyclient.NewDiscountIndex(c)
+	di.Start(5*time.Millisecond, func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+
+	select {
+	case err := <-errs:
+		assert.NotNil(t, err)
+	case <-time.After(time.Second):
+		assert.FailNow(t, "index never refreshed")
+	}
+	di.Stop()
+
+	assert.True(t, atomic.LoadInt32(&requests) > 0)
+	assert.True(t, di.RefreshedOn().IsZero(), "a failed refresh should not update RefreshedOn")
+}
+
+func TestDiscountIndexStartLoadsImmediately(t *testing.T) {
+	var requests int32
+	handlers := map[string]http.HandlerFunc{
+		"/v1/discounts": func(res http.ResponseWriter, req *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			res.Write([]byte(buildCodedDiscountsResponse(t)))
+		},
+	}
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	di := dairyclient.NewDiscountIndex(c)
+	di.Start(time.Hour, nil)
+	di.Start(time.Hour, nil)
+	defer di.Stop()
+
+	d, err := di.Lookup("active", time.Now())
+	assert.Nil(t, err, "codes should resolve before the first tick")
+	assert.Equal(t, uint64(1), d.ID)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests), "starting twice should not start a second refresher")
+}
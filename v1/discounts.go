@@ -1,9 +1,18 @@
 package dairyclient
 
 import (
+	"strings"
+	"time"
+
+	"github.com/dairycart/dairyclient/v1/pricing"
 	"github.com/dairycart/dairymodels/v1"
+
+	"github.com/pkg/errors"
 )
 
+// ErrDiscountNotFound is returned when no discount matches a given code
+var ErrDiscountNotFound = errors.New("no discount found with that code")
+
 ////////////////////////////////////////////////////////
 //                                                    //
 //                Discount Functions                  //
@@ -13,49 +22,94 @@ import (
 func (dc *V1Client) GetDiscountByID(discountID uint64) (*models.Discount, error) {
 	discountIDString := convertIDToString(discountID)
 	u := dc.buildURL(nil, "discount", discountIDString)
-	d := models.Discount{}
+	return get[models.Discount](dc, u)
+}
 
-	err := dc.get(u, &d)
+// GetDiscounts retrieves a page of discounts matching a given query filter
+func (dc *V1Client) GetDiscounts(queryFilter map[string]string) ([]models.Discount, error) {
+	u := dc.buildURL(queryFilter, "discounts")
+	d, err := get[models.DiscountListResponse](dc, u)
 	if err != nil {
 		return nil, err
 	}
-	return &d, nil
+	return d.Discounts, nil
 }
 
-func (dc *V1Client) GetDiscounts(queryFilter map[string]string) ([]models.Discount, error) {
-	u := dc.buildURL(nil, "discounts")
-	d := &models.DiscountListResponse{}
+// getDiscountPage retrieves one page of the discount list, along with how many discounts the list holds
+func (dc *V1Client) getDiscountPage(page uint64) ([]models.Discount, uint64, error) {
+	u := dc.buildURL(map[string]string{"page": convertIDToString(page)}, "discounts")
+	dl, err := get[models.DiscountListResponse](dc, u)
+	if err != nil {
+		return nil, 0, err
+	}
+	return dl.Discounts, uint64(dl.Count), nil
+}
 
-	err := dc.get(u, &d)
+// GetDiscountByCode pages through the discount list for the discount whose code matches the one provided,
+// ignoring case, stopping at the first match
+func (dc *V1Client) GetDiscountByCode(code string) (*models.Discount, error) {
+	var found *models.Discount
+	_, err := getAll(func(page uint64) ([]models.Discount, uint64, error) {
+		discounts, count, err := dc.getDiscountPage(page)
+		if err != nil {
+			return nil, 0, err
+		}
+		for i := range discounts {
+			if strings.EqualFold(discounts[i].Code, code) {
+				found = &discounts[i]
+				// an empty page tells getAll to stop
+				return nil, 0, nil
+			}
+		}
+		return discounts, count, nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	return d.Discounts, nil
+	if found == nil {
+		return nil, ErrDiscountNotFound
+	}
+	return found, nil
 }
 
-func (dc *V1Client) CreateDiscount(nd models.DiscountCreationInput) (*models.Discount, error) {
-	d := models.Discount{}
-	u := dc.buildURL(nil, "discount")
+// GetAllDiscounts pages through the discount list until every discount has been retrieved
+func (dc *V1Client) GetAllDiscounts() ([]models.Discount, error) {
+	return getAll(dc.getDiscountPage)
+}
 
-	err := dc.post(u, nd, &d)
+// GetActiveDiscounts retrieves every discount that is started, unexpired, unarchived and not exhausted at a given time
+func (dc *V1Client) GetActiveDiscounts(at time.Time) ([]models.Discount, error) {
+	discounts, err := dc.GetAllDiscounts()
 	if err != nil {
 		return nil, err
 	}
 
-	return &d, nil
+	active := []models.Discount{}
+	for _, d := range discounts {
+		if pricing.CheckActive(d, at) == nil {
+			active = append(active, d)
+		}
+	}
+	return active, nil
+}
+
+func (dc *V1Client) CreateDiscount(nd models.DiscountCreationInput) (*models.Discount, error) {
+	if err := dc.validate(nd); err != nil {
+		return nil, err
+	}
+	u := dc.buildURL(nil, "discount")
+	return post[models.Discount](dc, u, nd)
 }
 
 func (dc *V1Client) UpdateDiscount(discountID uint64, ud models.DiscountUpdateInput) (*models.Discount, error) {
-	d := models.Discount{}
+	return dc.updateDiscount(discountID, ud)
+}
+
+// updateDiscount sends a discount update, either a DiscountUpdateInput or a DiscountPatch
+func (dc *V1Client) updateDiscount(discountID uint64, body interface{}) (*models.Discount, error) {
 	discountIDString := convertIDToString(discountID)
 	u := dc.buildURL(nil, "discount", discountIDString)
-
-	err := dc.patch(u, ud, &d)
-	if err != nil {
-		return nil, err
-	}
-
-	return &d, nil
+	return patch[models.Discount](dc, u, body)
 }
 
 func (dc *V1Client) DeleteDiscount(discountID uint64) error {
@@ -63,3 +117,10 @@ func (dc *V1Client) DeleteDiscount(discountID uint64) error {
 	u := dc.buildURL(nil, "discount", discountIDString)
 	return dc.delete(u)
 }
+
+// RestoreDiscount undoes the archival of a discount
+func (dc *V1Client) RestoreDiscount(discountID uint64) (*models.Discount, error) {
+	discountIDString := convertIDToString(discountID)
+	u := dc.buildURL(nil, "discount", discountIDString, "restore")
+	return post[models.Discount](dc, u, struct{}{})
+}
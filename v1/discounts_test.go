@@ -5,10 +5,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/dairycart/dairyclient/v1"
 	"github.com/dairycart/dairymodels/v1"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func buildNotFoundDiscountResponse(id uint64) string {
@@ -234,3 +237,155 @@ func TestDeleteDiscount(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 }
+
+func buildCodedDiscountsResponse(t *testing.T) string {
+	t.Helper()
+	now := time.Now().UTC()
+	past := now.Add(-24 * time.Hour).Format(timeLayout)
+	future := now.Add(24 * time.Hour).Format(timeLayout)
+
+	return fmt.Sprintf(`
+		{
+			"count": 3,
+			"limit": 25,
+			"page": 1,
+			"discounts": [
+				{
+					"id": 1,
+					"name": "active",
+					"code": "ACTIVE",
+					"discount_type": "percentage",
+					"amount": 10,
+					"requires_code": true,
+					"starts_on": "%s",
+					"expires_on": "%s",
+					"created_on": "%s"
+				},
+				{
+					"id": 2,
+					"name": "expired",
+					"code": "EXPIRED",
+					"discount_type": "percentage",
+					"amount": 10,
+					"requires_code": true,
+					"starts_on": "%s",
+					"expires_on": "%s",
+					"created_on": "%s"
+				},
+				{
+					"id": 3,
+					"name": "exhausted",
+					"code": "EXHAUSTED",
+					"discount_type": "flat_amount",
+					"amount": 5,
+					"requires_code": true,
+					"limited_use": true,
+					"number_of_uses": 0,
+					"starts_on": "%s",
+					"expires_on": null,
+					"created_on": "%s"
+				}
+			]
+		}
+	`, past, future, past, past, past, past, past, past)
+}
+
+func TestGetDiscountByCode(t *testing.T) {
+	// the server's default limit makes these pages shorter than the whole list
+	pages := map[string]string{
+		"1": `{"count": 3, "limit": 2, "page": 1, "discounts": [{"id": 1, "code": "ACTIVE"}, {"id": 2, "code": "EXPIRED"}]}`,
+		"2": `{"count": 3, "limit": 2, "page": 2, "discounts": [{"id": 3, "code": "LATER"}]}`,
+	}
+
+	var requestedPages []string
+	handlers := map[string]http.HandlerFunc{
+		"/v1/discounts": func(res http.ResponseWriter, req *http.Request) {
+			page := req.URL.Query().Get("page")
+			requestedPages = append(requestedPages, page)
+			fmt.Fprint(res, pages[page])
+		},
+	}
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("normal operation", func(*testing.T) {
+		requestedPages = nil
+		actual, err := c.GetDiscountByCode("active")
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1), actual.ID)
+		assert.Equal(t, []string{"1"}, requestedPages, "paging should stop at the first match")
+	})
+
+	t.Run("on a later page", func(*testing.T) {
+		actual, err := c.GetDiscountByCode("later")
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(3), actual.ID)
+	})
+
+	t.Run("with unknown code", func(*testing.T) {
+		requestedPages = nil
+		_, err := c.GetDiscountByCode("nope")
+		assert.Equal(t, dairyclient.ErrDiscountNotFound, err)
+		assert.Equal(t, []string{"1", "2"}, requestedPages)
+	})
+
+	t.Run("with error response", func(*testing.T) {
+		ts.Close()
+		_, err := c.GetDiscountByCode("active")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestGetActiveDiscounts(t *testing.T) {
+	t.Run("normal operation", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/discounts": generateGetHandler(t, buildCodedDiscountsResponse(t), http.StatusOK),
+		}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		actual, err := c.GetActiveDiscounts(time.Now())
+		assert.Nil(t, err)
+		require.Len(t, actual, 1)
+		assert.Equal(t, uint64(1), actual[0].ID)
+	})
+
+	t.Run("with error response", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/discounts": generateGetHandler(t, exampleBadJSON, http.StatusOK),
+		}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		_, err := c.GetActiveDiscounts(time.Now())
+		assert.NotNil(t, err)
+	})
+}
+
+func TestRestoreDiscount(t *testing.T) {
+	existentID, nonexistentID := uint64(1), uint64(2)
+	exampleResponseJSON := loadExampleResponse(t, "restored_discount")
+
+	handlers := map[string]http.HandlerFunc{
+		fmt.Sprintf("/v1/discount/%d/restore", existentID):    generatePostHandler(t, "{}", exampleResponseJSON, http.StatusOK),
+		fmt.Sprintf("/v1/discount/%d/restore", nonexistentID): generatePostHandler(t, "{}", buildNotFoundDiscountResponse(nonexistentID), http.StatusNotFound),
+	}
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("with archived discount", func(*testing.T) {
+		actual, err := c.RestoreDiscount(existentID)
+		assert.Nil(t, err)
+		assert.Nil(t, actual.ArchivedOn)
+		assert.Equal(t, buildTestDairytime(t), actual.UpdatedOn)
+	})
+
+	t.Run("with nonexistent discount", func(*testing.T) {
+		_, err := c.RestoreDiscount(nonexistentID)
+		assert.NotNil(t, err)
+	})
+}
@@ -3,15 +3,14 @@ package dairyclient
 import (
 	"bytes"
 	"encoding/json"
-	"errors"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"net/url"
-	"reflect"
 	"strconv"
 
 	"github.com/dairycart/dairymodels/v1"
+
+	"github.com/pkg/errors"
 )
 
 type ClientError struct {
@@ -43,48 +42,94 @@ func mapToQueryValues(in map[string]string) url.Values {
 	return out
 }
 
-func interfaceArgIsNotPointerOrNil(i interface{}) error {
-	if i == nil {
-		return errors.New("unmarshalBody cannot accept nil values")
+// IncludeArchivedKey is the query parameter that asks list endpoints to return archived entities alongside active ones
+const IncludeArchivedKey = "include_archived"
+
+// IncludeArchived returns a copy of a query filter that also asks for archived entities
+func IncludeArchived(queryFilter map[string]string) map[string]string {
+	out := map[string]string{IncludeArchivedKey: "true"}
+	for k, v := range queryFilter {
+		if k != IncludeArchivedKey {
+			out[k] = v
+		}
 	}
-	isNotPtr := reflect.TypeOf(i).Kind() != reflect.Ptr
-	if isNotPtr {
-		return errors.New("unmarshalBody can only accept pointers")
+	return out
+}
+
+// getAll calls fetch with increasing page numbers until it has retrieved as many entities as the list endpoint
+// counts, or a page comes back empty. Paging by the count rather than by the page size means the server's
+// default limit never cuts a listing short.
+func getAll[T any](fetch func(page uint64) (items []T, count uint64, err error)) ([]T, error) {
+	var all []T
+	for page := uint64(1); ; page++ {
+		items, count, err := fetch(page)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+
+		if len(items) == 0 || uint64(len(all)) >= count {
+			return all, nil
+		}
 	}
-	return nil
 }
 
-func unmarshalBody(res *http.Response, dest interface{}) *ClientError {
-	ce := &ClientError{}
+// ErrResponseTooLarge is returned when a response body is longer than the client's MaxResponseSize
+var ErrResponseTooLarge = errors.New("response body exceeds the maximum allowed size")
+
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
 
-	// These paths should only ever be reached in tests, and should never be encountered by an end user.
-	if err := interfaceArgIsNotPointerOrNil(dest); err != nil {
-		ce.Err = err
-		return ce
+// limitBody wraps a response body so that reading more than max bytes from it fails with ErrResponseTooLarge
+func limitBody(body io.ReadCloser, max int64) io.ReadCloser {
+	return &limitedBody{ReadCloser: body, remaining: max}
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		n, err := l.ReadCloser.Read(make([]byte, 1))
+		if n > 0 {
+			return 0, ErrResponseTooLarge
+		}
+		return 0, err
 	}
 
-	bodyBytes, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		ce.Err = err
-		return ce
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
 	}
+	n, err := l.ReadCloser.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
 
-	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
+func isErrorStatus(status int) bool {
+	return status < http.StatusOK || status >= http.StatusBadRequest
+}
+
+// unmarshalBody decodes a response into a new T, or into a ClientError if the response has an error status
+func unmarshalBody[T any](res *http.Response) (*T, *ClientError) {
+	defer res.Body.Close()
+
+	dec := json.NewDecoder(res.Body)
+	if isErrorStatus(res.StatusCode) {
 		apiErr := &models.ErrorResponse{}
-		// eating this error because it would have been caught above
-		err = json.Unmarshal(bodyBytes, &apiErr)
-		if err != nil {
-			return &ClientError{Err: err}
+		if err := dec.Decode(apiErr); err != nil {
+			return nil, &ClientError{Err: err}
 		}
-		return &ClientError{FromAPI: apiErr}
+		return nil, &ClientError{FromAPI: apiErr}
 	}
 
-	err = json.Unmarshal(bodyBytes, &dest)
-	if err != nil {
-		return &ClientError{Err: err}
+	dest := new(T)
+	if res.StatusCode == http.StatusNoContent {
+		return dest, nil
+	}
+	if err := dec.Decode(dest); err != nil {
+		return nil, &ClientError{Err: errors.Wrap(err, "encountered error loading response from server")}
 	}
 
-	return nil
+	return dest, nil
 }
 
 func convertIDToString(id uint64) string {
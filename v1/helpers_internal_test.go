@@ -49,6 +49,22 @@ func TestMapToQueryValues(t *testing.T) {
 	assert.Equal(t, expected, actual, "expected and actual url values should be equal")
 }
 
+func TestIncludeArchived(t *testing.T) {
+	t.Run("with existing filter", func(*testing.T) {
+		original := map[string]string{"page": "2", IncludeArchivedKey: "false"}
+		expected := map[string]string{"page": "2", IncludeArchivedKey: "true"}
+
+		actual := IncludeArchived(original)
+		assert.Equal(t, expected, actual)
+		assert.Equal(t, "false", original[IncludeArchivedKey], "IncludeArchived should not modify the filter it is given")
+	})
+
+	t.Run("with nil filter", func(*testing.T) {
+		expected := map[string]string{IncludeArchivedKey: "true"}
+		assert.Equal(t, expected, IncludeArchived(nil))
+	})
+}
+
 type testNormalStruct struct {
 	Thing string `json:"thing"`
 }
@@ -66,58 +82,42 @@ func TestUnmarshalBody(t *testing.T) {
 			Body: ioutil.NopCloser(bytes.NewBufferString(`{"thing":"something"}`)),
 		}
 
-		expected := testNormalStruct{Thing: "something"}
-		actual := testNormalStruct{}
-		err := unmarshalBody(exampleInput, &actual)
+		expected := &testNormalStruct{Thing: "something"}
+		actual, err := unmarshalBody[testNormalStruct](exampleInput)
 
 		assert.Nil(t, err)
 		assert.Equal(t, expected, actual, "expected and actual unmarshaled structs should match")
 	})
 
-	t.Run("should fail when receiving nil", func(*testing.T) {
-		exampleFailureInput := &http.Response{
-			StatusCode: http.StatusOK,
-			Body: ioutil.NopCloser(testFailReader{}),
-		}
-
-		err := unmarshalBody(exampleFailureInput, nil)
-		assert.NotNil(t, err)
-		expected := &ClientError{Err: errors.New("unmarshalBody cannot accept nil values")}
-		assert.Equal(t, expected, err, "expected error string %s")
-	})
-
-	t.Run("fails when it receives a non pointer", func(*testing.T) {
+	t.Run("returns ReadAll error", func(*testing.T) {
 		exampleFailureInput := &http.Response{
 			StatusCode: http.StatusOK,
 			Body: ioutil.NopCloser(testFailReader{}),
 		}
 
-		err := unmarshalBody(exampleFailureInput, testNormalStruct{})
+		_, err := unmarshalBody[testNormalStruct](exampleFailureInput)
 		assert.NotNil(t, err)
-		expected := &ClientError{Err: errors.New("unmarshalBody can only accept pointers")}
-		assert.Equal(t, expected, err)
 	})
 
-	t.Run("returns ReadAll error", func(*testing.T) {
-		exampleFailureInput := &http.Response{
+	t.Run("with invalid struct", func(*testing.T) {
+		exampleInput := &http.Response{
 			StatusCode: http.StatusOK,
-			Body: ioutil.NopCloser(testFailReader{}),
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"invalid_lol}`)),
 		}
 
-		err := unmarshalBody(exampleFailureInput, &testNormalStruct{})
+		_, err := unmarshalBody[testNormalStruct](exampleInput)
 		assert.NotNil(t, err)
 	})
 
-	t.Run("with invalid struct", func(*testing.T) {
+	t.Run("with API error", func(*testing.T) {
 		exampleInput := &http.Response{
-			StatusCode: http.StatusOK,
-			Body: ioutil.NopCloser(bytes.NewBufferString(`{"invalid_lol}`)),
+			StatusCode: http.StatusNotFound,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"status":404,"message":"not found"}`)),
 		}
 
-		actual := testNormalStruct{}
-		err := unmarshalBody(exampleInput, &actual)
-
+		_, err := unmarshalBody[testNormalStruct](exampleInput)
 		assert.NotNil(t, err)
+		assert.Equal(t, &models.ErrorResponse{Status: 404, Message: "not found"}, err.FromAPI)
 	})
 }
 
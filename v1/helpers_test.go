@@ -69,7 +69,7 @@ func buildTestClient(t *testing.T, ts *httptest.Server) *dairyclient.V1Client {
 	return c
 }
 
-func loadExampleResponse(t *testing.T, name string) string {
+func loadExampleResponse(t testing.TB, name string) string {
 	t.Helper()
 	data, err := ioutil.ReadFile(fmt.Sprintf("example_responses/%s.json", name))
 	if err != nil {
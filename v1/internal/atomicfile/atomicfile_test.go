@@ -0,0 +1,34 @@
+package atomicfile_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/dairycart/dairyclient/v1/internal/atomicfile"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.json")
+
+	t.Run("replaces existing contents", func(*testing.T) {
+		require.Nil(t, atomicfile.WriteFile(path, []byte("old")))
+		require.Nil(t, atomicfile.WriteFile(path, []byte("new")))
+
+		data, err := ioutil.ReadFile(path)
+		require.Nil(t, err)
+		assert.Equal(t, "new", string(data))
+
+		entries, err := ioutil.ReadDir(dir)
+		require.Nil(t, err)
+		assert.Len(t, entries, 1, "no temporary files should be left behind")
+	})
+
+	t.Run("with missing directory", func(*testing.T) {
+		assert.NotNil(t, atomicfile.WriteFile(filepath.Join(dir, "nope", "data.json"), nil))
+	})
+}
@@ -0,0 +1,154 @@
+package dairyclient
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/dairycart/dairymodels/v1"
+
+	"github.com/pkg/errors"
+)
+
+// MaxInventoryRetries is how many times an inventory helper will re-read a product and try again after the
+// server rejects its write with 412 Precondition Failed
+const MaxInventoryRetries = 5
+
+var (
+	// ErrInsufficientStock is returned when a change would take a product's quantity below zero
+	ErrInsufficientStock = errors.New("not enough stock to satisfy the requested change")
+	// ErrQuantityOverflow is returned when a change would take a product's quantity past what it can hold
+	ErrQuantityOverflow = errors.New("requested change exceeds the maximum product quantity")
+	// ErrVersionConflict is returned when a product kept changing underneath an inventory helper until it ran out of retries
+	ErrVersionConflict = errors.New("product was modified by another writer on every attempt")
+)
+
+type quantityUpdateInput struct {
+	Quantity uint32 `json:"quantity"`
+}
+
+// productVersion derives a version tag for a product from the last time it changed. This format is the client's
+// own; the Dairycart API documents no ETag for products, so a server has to be taught to compare it.
+func productVersion(p *models.Product) string {
+	t := p.CreatedOn
+	if p.UpdatedOn != nil && !p.UpdatedOn.Time.IsZero() {
+		t = p.UpdatedOn.Time
+	}
+	return fmt.Sprintf("%q", t.UTC().Format(time.RFC3339Nano))
+}
+
+func isVersionConflict(err error) bool {
+	ce, ok := err.(*ClientError)
+	return ok && ce.FromAPI != nil && ce.FromAPI.Status == http.StatusPreconditionFailed
+}
+
+// updateQuantity reads a product, computes its new quantity, and writes it back with the version it read as an
+// If-Match header. This only guards against lost updates on a server that honors conditional requests; if the
+// write is rejected with 412 Precondition Failed, the product is re-read and the computation retried. A server that
+// ignores If-Match will accept the write as is.
+func (dc *V1Client) updateQuantity(sku string, compute func(current int64) (int64, error)) (*models.Product, error) {
+	if err := requireIdentifier("sku", sku); err != nil {
+		return nil, err
+	}
+	u := dc.buildURL(nil, "product", sku)
+
+	for attempt := 0; attempt < MaxInventoryRetries; attempt++ {
+		current, err := dc.GetProduct(sku)
+		if err != nil {
+			return nil, err
+		}
+
+		next, err := compute(int64(current.Quantity))
+		if err != nil {
+			return nil, err
+		}
+		if next < 0 {
+			return nil, ErrInsufficientStock
+		}
+		if next > math.MaxUint32 {
+			return nil, ErrQuantityOverflow
+		}
+
+		headers := map[string]string{"If-Match": productVersion(current)}
+		p, err := doJSON[models.Product](context.Background(), dc, http.MethodPatch, u, headers, quantityUpdateInput{Quantity: uint32(next)})
+		if err == nil {
+			return p, nil
+		}
+		if !isVersionConflict(err) {
+			return nil, err
+		}
+	}
+
+	return nil, ErrVersionConflict
+}
+
+// AdjustQuantity adds delta (which may be negative) to a product's quantity. The read and the write are separate
+// requests, so this is not atomic unless the server rejects stale If-Match headers; otherwise a concurrent change
+// can be overwritten.
+func (dc *V1Client) AdjustQuantity(sku string, delta int64) (*models.Product, error) {
+	return dc.updateQuantity(sku, func(current int64) (int64, error) {
+		return current + delta, nil
+	})
+}
+
+// SetQuantity sets a product's quantity to an absolute value. Like AdjustQuantity, it is only safe against
+// concurrent writers on a server that honors If-Match.
+func (dc *V1Client) SetQuantity(sku string, quantity uint32) (*models.Product, error) {
+	return dc.updateQuantity(sku, func(int64) (int64, error) {
+		return int64(quantity), nil
+	})
+}
+
+// ReserveQuantity removes quantity units from a product's stock, failing with ErrInsufficientStock rather than
+// reserving a partial amount. The stock check happens client-side, so on a server that ignores If-Match two
+// concurrent reservations can both succeed and oversell the product without any error.
+func (dc *V1Client) ReserveQuantity(sku string, quantity uint32) (*models.Product, error) {
+	return dc.updateQuantity(sku, func(current int64) (int64, error) {
+		if current < int64(quantity) {
+			return 0, ErrInsufficientStock
+		}
+		return current - int64(quantity), nil
+	})
+}
+
+// getAllProducts pages through the product list until every product matching queryFilter has been retrieved
+func (dc *V1Client) getAllProducts(queryFilter map[string]string) ([]models.Product, error) {
+	filter := map[string]string{}
+	for k, v := range queryFilter {
+		filter[k] = v
+	}
+
+	return getAll(func(page uint64) ([]models.Product, uint64, error) {
+		filter["page"] = convertIDToString(page)
+		u := dc.buildURL(filter, "products")
+		pl, err := get[models.ProductListResponse](dc, u)
+		if err != nil {
+			return nil, 0, err
+		}
+		return pl.Products, uint64(pl.Count), nil
+	})
+}
+
+// GetLowStockProducts scans every product in the store and returns those whose quantity is below threshold,
+// lowest quantity first
+func (dc *V1Client) GetLowStockProducts(threshold uint32) ([]models.Product, error) {
+	products, err := dc.getAllProducts(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	low := []models.Product{}
+	for _, p := range products {
+		if uint32(p.Quantity) < threshold {
+			low = append(low, p)
+		}
+	}
+
+	sort.SliceStable(low, func(i, j int) bool {
+		return low[i].Quantity < low[j].Quantity
+	})
+	return low, nil
+}
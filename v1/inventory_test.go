@@ -0,0 +1,217 @@
+package dairyclient_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dairycart/dairyclient/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+////////////////////////////////////////////////////////
+//                                                    //
+//             Inventory Function Tests               //
+//                                                    //
+////////////////////////////////////////////////////////
+
+// inventoryServer simulates a single product whose quantity can be changed by conditional PATCH requests
+type inventoryServer struct {
+	t         *testing.T
+	quantity  uint32
+	version   int
+	conflicts int
+	patches   []string
+}
+
+func (is *inventoryServer) productJSON() string {
+	return fmt.Sprintf(`
+		{
+			"sku": "%s",
+			"quantity": %d,
+			"created_on": "2017-12-10T15:58:43.136458Z",
+			"updated_on": "2017-12-10T15:58:%02d.000000Z"
+		}
+	`, exampleSKU, is.quantity, is.version)
+}
+
+func (is *inventoryServer) handler() http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			fmt.Fprint(res, is.productJSON())
+		case http.MethodPatch:
+			bodyBytes, err := ioutil.ReadAll(req.Body)
+			require.Nil(is.t, err)
+			is.patches = append(is.patches, string(bodyBytes))
+
+			expectedVersion := fmt.Sprintf(`"2017-12-10T15:58:%02dZ"`, is.version)
+			if is.conflicts > 0 || req.Header.Get("If-Match") != expectedVersion {
+				// simulate another writer getting there first
+				is.conflicts--
+				is.version++
+				res.WriteHeader(http.StatusPreconditionFailed)
+				fmt.Fprintf(res, `{"status":412,"message":"product has changed"}`)
+				return
+			}
+
+			update := struct {
+				Quantity uint32 `json:"quantity"`
+			}{}
+			require.Nil(is.t, json.Unmarshal(bodyBytes, &update))
+			is.quantity = update.Quantity
+			is.version++
+			fmt.Fprint(res, is.productJSON())
+		}
+	}
+}
+
+func buildInventoryTestClient(t *testing.T, is *inventoryServer) (*dairyclient.V1Client, *httptest.Server) {
+	t.Helper()
+	handlers := map[string]http.HandlerFunc{
+		fmt.Sprintf("/v1/product/%s", exampleSKU): is.handler(),
+	}
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	return buildTestClient(t, ts), ts
+}
+
+func TestAdjustQuantity(t *testing.T) {
+	t.Run("normal usage", func(*testing.T) {
+		is := &inventoryServer{t: t, quantity: 10, version: 1}
+		c, ts := buildInventoryTestClient(t, is)
+		defer ts.Close()
+
+		actual, err := c.AdjustQuantity(exampleSKU, -3)
+		assert.Nil(t, err)
+		assert.Equal(t, uint32(7), uint32(actual.Quantity))
+		assert.Equal(t, []string{`{"quantity":7}`}, is.patches)
+	})
+
+	t.Run("retries after version conflict", func(*testing.T) {
+		is := &inventoryServer{t: t, quantity: 10, version: 1, conflicts: 2}
+		c, ts := buildInventoryTestClient(t, is)
+		defer ts.Close()
+
+		actual, err := c.AdjustQuantity(exampleSKU, 5)
+		assert.Nil(t, err)
+		assert.Equal(t, uint32(15), uint32(actual.Quantity))
+		assert.Len(t, is.patches, 3)
+	})
+
+	t.Run("gives up after too many conflicts", func(*testing.T) {
+		is := &inventoryServer{t: t, quantity: 10, version: 1, conflicts: dairyclient.MaxInventoryRetries}
+		c, ts := buildInventoryTestClient(t, is)
+		defer ts.Close()
+
+		_, err := c.AdjustQuantity(exampleSKU, 5)
+		assert.Equal(t, dairyclient.ErrVersionConflict, err)
+		assert.Len(t, is.patches, dairyclient.MaxInventoryRetries)
+	})
+
+	t.Run("below zero", func(*testing.T) {
+		is := &inventoryServer{t: t, quantity: 2, version: 1}
+		c, ts := buildInventoryTestClient(t, is)
+		defer ts.Close()
+
+		_, err := c.AdjustQuantity(exampleSKU, -3)
+		assert.Equal(t, dairyclient.ErrInsufficientStock, err)
+		assert.Empty(t, is.patches)
+	})
+
+	t.Run("past the maximum quantity", func(*testing.T) {
+		is := &inventoryServer{t: t, quantity: 2, version: 1}
+		c, ts := buildInventoryTestClient(t, is)
+		defer ts.Close()
+
+		_, err := c.AdjustQuantity(exampleSKU, math.MaxUint32)
+		assert.Equal(t, dairyclient.ErrQuantityOverflow, err)
+		assert.Empty(t, is.patches)
+	})
+
+	t.Run("with nonexistent product", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			fmt.Sprintf("/v1/product/%s", exampleSKU): generateGetHandler(t, buildNotFoundProductResponse(exampleSKU), http.StatusNotFound),
+		}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		_, err := c.AdjustQuantity(exampleSKU, 1)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestSetQuantity(t *testing.T) {
+	is := &inventoryServer{t: t, quantity: 10, version: 1}
+	c, ts := buildInventoryTestClient(t, is)
+	defer ts.Close()
+
+	actual, err := c.SetQuantity(exampleSKU, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(0), uint32(actual.Quantity))
+	assert.Equal(t, []string{`{"quantity":0}`}, is.patches, "a zero quantity should still be sent")
+}
+
+func TestReserveQuantity(t *testing.T) {
+	t.Run("normal usage", func(*testing.T) {
+		is := &inventoryServer{t: t, quantity: 10, version: 1}
+		c, ts := buildInventoryTestClient(t, is)
+		defer ts.Close()
+
+		actual, err := c.ReserveQuantity(exampleSKU, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, uint32(0), uint32(actual.Quantity))
+	})
+
+	t.Run("with insufficient stock", func(*testing.T) {
+		is := &inventoryServer{t: t, quantity: 10, version: 1}
+		c, ts := buildInventoryTestClient(t, is)
+		defer ts.Close()
+
+		_, err := c.ReserveQuantity(exampleSKU, 11)
+		assert.Equal(t, dairyclient.ErrInsufficientStock, err)
+		assert.Equal(t, uint32(10), is.quantity)
+	})
+}
+
+func TestGetLowStockProducts(t *testing.T) {
+	pages := map[string]string{
+		"1": `{"count": 3, "limit": 2, "page": 1, "products": [{"sku": "a", "quantity": 5}, {"sku": "b", "quantity": 1}]}`,
+		"2": `{"count": 3, "limit": 2, "page": 2, "products": [{"sku": "c", "quantity": 50}]}`,
+	}
+
+	t.Run("normal usage", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/products": func(res http.ResponseWriter, req *http.Request) {
+				fmt.Fprint(res, pages[req.URL.Query().Get("page")])
+			},
+		}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		actual, err := c.GetLowStockProducts(10)
+		assert.Nil(t, err)
+		require.Len(t, actual, 2)
+		assert.Equal(t, "b", actual[0].SKU)
+		assert.Equal(t, "a", actual[1].SKU)
+	})
+
+	t.Run("with error response", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/products": generateGetHandler(t, exampleBadJSON, http.StatusOK),
+		}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		_, err := c.GetLowStockProducts(10)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,214 @@
+package dairyclient
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/dairycart/dairymodels/v1"
+
+	"github.com/pkg/errors"
+)
+
+// These are the statuses an order can be in
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusPaid      = "paid"
+	OrderStatusShipped   = "shipped"
+	OrderStatusDelivered = "delivered"
+	OrderStatusCancelled = "cancelled"
+	OrderStatusRefunded  = "refunded"
+)
+
+// Order represents a completed checkout
+type Order struct {
+	ID         uint64            `json:"id"`
+	UserID     uint64            `json:"user_id"`
+	Status     string            `json:"status"`
+	LineItems  []OrderLineItem   `json:"line_items"`
+	Subtotal   float32           `json:"subtotal"`
+	Discount   float32           `json:"discount"`
+	Tax        float32           `json:"tax"`
+	Total      float32           `json:"total"`
+	Refunded   float32           `json:"refunded"`
+	CreatedOn  time.Time         `json:"created_on"`
+	UpdatedOn  *models.Dairytime `json:"updated_on"`
+	ArchivedOn *models.Dairytime `json:"archived_on"`
+}
+
+// OrderLineItem represents a quantity of a single SKU within an order
+type OrderLineItem struct {
+	ID        uint64  `json:"id"`
+	OrderID   uint64  `json:"order_id"`
+	ProductID uint64  `json:"product_id"`
+	SKU       string  `json:"sku"`
+	Name      string  `json:"name"`
+	Quantity  uint32  `json:"quantity"`
+	UnitPrice float32 `json:"unit_price"`
+	Total     float32 `json:"total"`
+}
+
+// OrderListResponse is the paginated response returned when listing orders
+type OrderListResponse struct {
+	Count  uint64  `json:"count"`
+	Limit  uint64  `json:"limit"`
+	Page   uint64  `json:"page"`
+	Orders []Order `json:"orders"`
+}
+
+// OrderStatusUpdateInput is the body sent when changing an order's status
+type OrderStatusUpdateInput struct {
+	Status string `json:"status"`
+}
+
+// OrderRefundInput is the body sent when refunding an order. A zero Amount refunds the full remaining total
+type OrderRefundInput struct {
+	Amount float32 `json:"amount,omitempty"`
+	Reason string  `json:"reason,omitempty"`
+}
+
+// OrderFilter narrows the orders returned by GetOrders. Zero values are ignored
+type OrderFilter struct {
+	Status        string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	Page          uint64
+	Limit         uint64
+}
+
+func (of OrderFilter) queryFilter() map[string]string {
+	out := map[string]string{}
+	if of.Status != "" {
+		out["status"] = of.Status
+	}
+	if !of.CreatedAfter.IsZero() {
+		out["created_after"] = strconv.FormatInt(of.CreatedAfter.Unix(), 10)
+	}
+	if !of.CreatedBefore.IsZero() {
+		out["created_before"] = strconv.FormatInt(of.CreatedBefore.Unix(), 10)
+	}
+	if of.Page != 0 {
+		out["page"] = convertIDToString(of.Page)
+	}
+	if of.Limit != 0 {
+		out["limit"] = convertIDToString(of.Limit)
+	}
+	return out
+}
+
+////////////////////////////////////////////////////////
+//                                                    //
+//                  Order Functions                   //
+//                                                    //
+////////////////////////////////////////////////////////
+
+// GetOrder retrieves an order with a given ID
+func (dc *V1Client) GetOrder(orderID uint64) (*Order, error) {
+	orderIDString := convertIDToString(orderID)
+	u := dc.buildURL(nil, "order", orderIDString)
+	return get[Order](dc, u)
+}
+
+// GetOrders retrieves a page of orders matching a given filter
+func (dc *V1Client) GetOrders(filter OrderFilter) (*OrderListResponse, error) {
+	u := dc.buildURL(filter.queryFilter(), "orders")
+	return get[OrderListResponse](dc, u)
+}
+
+// UpdateOrderStatus moves an order with a given ID into a new status
+func (dc *V1Client) UpdateOrderStatus(orderID uint64, status string) (*Order, error) {
+	orderIDString := convertIDToString(orderID)
+	u := dc.buildURL(nil, "order", orderIDString)
+	return patch[Order](dc, u, OrderStatusUpdateInput{Status: status})
+}
+
+// CancelOrder cancels an order with a given ID
+func (dc *V1Client) CancelOrder(orderID uint64) (*Order, error) {
+	orderIDString := convertIDToString(orderID)
+	u := dc.buildURL(nil, "order", orderIDString, "cancel")
+	return post[Order](dc, u, struct{}{})
+}
+
+// RefundOrder refunds some or all of an order with a given ID
+func (dc *V1Client) RefundOrder(orderID uint64, ri OrderRefundInput) (*Order, error) {
+	orderIDString := convertIDToString(orderID)
+	u := dc.buildURL(nil, "order", orderIDString, "refund")
+	return post[Order](dc, u, ri)
+}
+
+////////////////////////////////////////////////////////
+//                                                    //
+//                  Order Exporting                   //
+//                                                    //
+////////////////////////////////////////////////////////
+
+var orderExportHeader = []string{
+	"order_id",
+	"user_id",
+	"status",
+	"created_on",
+	"item_count",
+	"subtotal",
+	"discount",
+	"tax",
+	"total",
+	"refunded",
+}
+
+func formatMoney(f float32) string {
+	return strconv.FormatFloat(float64(f), 'f', 2, 32)
+}
+
+func orderToExportRow(o Order) []string {
+	var itemCount uint64
+	for _, li := range o.LineItems {
+		itemCount += uint64(li.Quantity)
+	}
+
+	return []string{
+		convertIDToString(o.ID),
+		convertIDToString(o.UserID),
+		o.Status,
+		o.CreatedOn.UTC().Format(time.RFC3339),
+		convertIDToString(itemCount),
+		formatMoney(o.Subtotal),
+		formatMoney(o.Discount),
+		formatMoney(o.Tax),
+		formatMoney(o.Total),
+		formatMoney(o.Refunded),
+	}
+}
+
+// WriteOrdersCSV writes one CSV row per order, preceded by a header row
+func WriteOrdersCSV(w io.Writer, orders []Order) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(orderExportHeader); err != nil {
+		return err
+	}
+	for _, o := range orders {
+		if err := cw.Write(orderToExportRow(o)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ExportOrders pages through every order matching a given filter and writes them to w as CSV.
+// The filter's Page field is ignored, and exporting always starts from the first page.
+func (dc *V1Client) ExportOrders(w io.Writer, filter OrderFilter) error {
+	orders, err := getAll(func(page uint64) ([]Order, uint64, error) {
+		filter.Page = page
+		ol, err := dc.GetOrders(filter)
+		if err != nil {
+			return nil, 0, errors.Wrapf(err, "encountered error fetching page %d of orders", page)
+		}
+		return ol.Orders, ol.Count, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return WriteOrdersCSV(w, orders)
+}
@@ -0,0 +1,294 @@
+package dairyclient_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dairycart/dairyclient/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+////////////////////////////////////////////////////////
+//                                                    //
+//               Order Function Tests                 //
+//                                                    //
+////////////////////////////////////////////////////////
+
+func buildNotFoundOrderResponse(orderID uint64) string {
+	return fmt.Sprintf(`
+		{
+			"status": 404,
+			"message": "The order you were looking for (order ID '%d') does not exist"
+		}
+	`, orderID)
+}
+
+func TestGetOrder(t *testing.T) {
+	existentID, nonexistentID := uint64(1), uint64(2)
+	exampleResponseJSON := loadExampleResponse(t, "order")
+
+	handlers := map[string]http.HandlerFunc{
+		fmt.Sprintf("/v1/order/%d", existentID):    generateGetHandler(t, exampleResponseJSON, http.StatusOK),
+		fmt.Sprintf("/v1/order/%d", nonexistentID): generateGetHandler(t, buildNotFoundOrderResponse(nonexistentID), http.StatusNotFound),
+	}
+
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("normal usage", func(*testing.T) {
+		expected := &dairyclient.Order{
+			ID:     1,
+			UserID: 1,
+			Status: dairyclient.OrderStatusPaid,
+			LineItems: []dairyclient.OrderLineItem{
+				{
+					ID:        1,
+					OrderID:   1,
+					ProductID: 1,
+					SKU:       "t-shirt-small-red",
+					Name:      "Your Favorite Band's T-Shirt",
+					Quantity:  2,
+					UnitPrice: 20,
+					Total:     40,
+				},
+			},
+			Subtotal:  40,
+			Discount:  4,
+			Tax:       2.88,
+			Total:     38.88,
+			CreatedOn: buildTestTime(t),
+		}
+
+		actual, err := c.GetOrder(existentID)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("with nonexistent order", func(*testing.T) {
+		_, err := c.GetOrder(nonexistentID)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestGetOrders(t *testing.T) {
+	exampleResponseJSON := loadExampleResponse(t, "orders")
+
+	t.Run("normal usage", func(*testing.T) {
+		var endpointCalled bool
+		handlers := map[string]http.HandlerFunc{
+			"/v1/orders": func(res http.ResponseWriter, req *http.Request) {
+				endpointCalled = true
+				query := req.URL.Query()
+				assert.Equal(t, dairyclient.OrderStatusPaid, query.Get("status"))
+				assert.Equal(t, "1512921523", query.Get("created_after"))
+				assert.Equal(t, "2", query.Get("page"))
+				assert.Empty(t, query.Get("created_before"))
+				fmt.Fprint(res, exampleResponseJSON)
+			},
+		}
+
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		filter := dairyclient.OrderFilter{
+			Status:       dairyclient.OrderStatusPaid,
+			CreatedAfter: buildTestTime(t),
+			Page:         2,
+		}
+		actual, err := c.GetOrders(filter)
+		assert.Nil(t, err)
+		assert.True(t, endpointCalled)
+		assert.Equal(t, uint64(2), actual.Count)
+		assert.Len(t, actual.Orders, 2)
+	})
+
+	t.Run("with bad server response", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/orders": generateGetHandler(t, exampleBadJSON, http.StatusOK),
+		}
+
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		_, err := c.GetOrders(dairyclient.OrderFilter{})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestUpdateOrderStatus(t *testing.T) {
+	existentID, nonexistentID := uint64(1), uint64(2)
+	exampleResponseJSON := loadExampleResponse(t, "updated_order")
+	expectedBody := `
+		{
+			"status": "cancelled"
+		}
+	`
+
+	handlers := map[string]http.HandlerFunc{
+		fmt.Sprintf("/v1/order/%d", existentID):    generatePatchHandler(t, expectedBody, exampleResponseJSON, http.StatusOK),
+		fmt.Sprintf("/v1/order/%d", nonexistentID): generatePatchHandler(t, expectedBody, buildNotFoundOrderResponse(nonexistentID), http.StatusNotFound),
+	}
+
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("normal usage", func(*testing.T) {
+		actual, err := c.UpdateOrderStatus(existentID, dairyclient.OrderStatusCancelled)
+		assert.Nil(t, err)
+		assert.Equal(t, dairyclient.OrderStatusCancelled, actual.Status)
+	})
+
+	t.Run("with nonexistent order", func(*testing.T) {
+		_, err := c.UpdateOrderStatus(nonexistentID, dairyclient.OrderStatusCancelled)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestCancelOrder(t *testing.T) {
+	existentID, nonexistentID := uint64(1), uint64(2)
+	exampleResponseJSON := loadExampleResponse(t, "updated_order")
+
+	handlers := map[string]http.HandlerFunc{
+		fmt.Sprintf("/v1/order/%d/cancel", existentID):    generatePostHandler(t, "{}", exampleResponseJSON, http.StatusOK),
+		fmt.Sprintf("/v1/order/%d/cancel", nonexistentID): generatePostHandler(t, "{}", buildNotFoundOrderResponse(nonexistentID), http.StatusNotFound),
+	}
+
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("normal usage", func(*testing.T) {
+		actual, err := c.CancelOrder(existentID)
+		assert.Nil(t, err)
+		assert.Equal(t, dairyclient.OrderStatusCancelled, actual.Status)
+	})
+
+	t.Run("with nonexistent order", func(*testing.T) {
+		_, err := c.CancelOrder(nonexistentID)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestRefundOrder(t *testing.T) {
+	existentID, nonexistentID := uint64(1), uint64(2)
+	exampleResponseJSON := loadExampleResponse(t, "updated_order")
+	expectedBody := `
+		{
+			"reason": "damaged"
+		}
+	`
+	exampleInput := dairyclient.OrderRefundInput{Reason: "damaged"}
+
+	handlers := map[string]http.HandlerFunc{
+		fmt.Sprintf("/v1/order/%d/refund", existentID):    generatePostHandler(t, expectedBody, exampleResponseJSON, http.StatusOK),
+		fmt.Sprintf("/v1/order/%d/refund", nonexistentID): generatePostHandler(t, expectedBody, buildNotFoundOrderResponse(nonexistentID), http.StatusNotFound),
+	}
+
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("normal usage", func(*testing.T) {
+		actual, err := c.RefundOrder(existentID, exampleInput)
+		assert.Nil(t, err)
+		assert.Equal(t, float32(38.88), actual.Refunded)
+	})
+
+	t.Run("with nonexistent order", func(*testing.T) {
+		_, err := c.RefundOrder(nonexistentID, exampleInput)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestWriteOrdersCSV(t *testing.T) {
+	orders := []dairyclient.Order{
+		{
+			ID:     1,
+			UserID: 2,
+			Status: dairyclient.OrderStatusPaid,
+			LineItems: []dairyclient.OrderLineItem{
+				{Quantity: 2},
+				{Quantity: 3},
+			},
+			Subtotal:  100,
+			Discount:  10,
+			Tax:       7.2,
+			Total:     97.2,
+			CreatedOn: time.Date(2017, 12, 10, 15, 58, 43, 0, time.UTC),
+		},
+	}
+	expected := "order_id,user_id,status,created_on,item_count,subtotal,discount,tax,total,refunded\n" +
+		"1,2,paid,2017-12-10T15:58:43Z,5,100.00,10.00,7.20,97.20,0.00\n"
+
+	buf := &bytes.Buffer{}
+	err := dairyclient.WriteOrdersCSV(buf, orders)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestExportOrders(t *testing.T) {
+	pageOne := `
+		{
+			"count": 2,
+			"limit": 1,
+			"page": 1,
+			"orders": [{"id": 1, "status": "paid", "created_on": "2017-12-10T15:58:43.136458Z"}]
+		}
+	`
+	pageTwo := `
+		{
+			"count": 2,
+			"limit": 1,
+			"page": 2,
+			"orders": [{"id": 2, "status": "paid", "created_on": "2017-12-10T15:58:43.136458Z"}]
+		}
+	`
+
+	t.Run("normal usage", func(*testing.T) {
+		var pagesRequested []string
+		handlers := map[string]http.HandlerFunc{
+			"/v1/orders": func(res http.ResponseWriter, req *http.Request) {
+				page := req.URL.Query().Get("page")
+				pagesRequested = append(pagesRequested, page)
+				if page == "1" {
+					fmt.Fprint(res, pageOne)
+				} else {
+					fmt.Fprint(res, pageTwo)
+				}
+			},
+		}
+
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		buf := &bytes.Buffer{}
+		err := c.ExportOrders(buf, dairyclient.OrderFilter{Limit: 1})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"1", "2"}, pagesRequested)
+		assert.Contains(t, buf.String(), "\n1,0,paid,")
+		assert.Contains(t, buf.String(), "\n2,0,paid,")
+	})
+
+	t.Run("with error response", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/orders": generateGetHandler(t, `{"status":500,"message":"oh no"}`, http.StatusInternalServerError),
+		}
+
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		err := c.ExportOrders(&bytes.Buffer{}, dairyclient.OrderFilter{})
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,261 @@
+package dairyclient
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/dairycart/dairymodels/v1"
+)
+
+// The patch builders in this file exist alongside UpdateProduct, UpdateProductOption, UpdateProductOptionValue
+// and UpdateDiscount because the update inputs those take are defined in dairymodels with omitempty fields, so
+// they can never send a zero value or a null. Changing the Update methods to take something else would break
+// every caller, so each Patch method instead sends its builder through the same request path as its Update
+// method, and only the body differs.
+
+// fieldSet holds the fields a patch builder has touched, keyed by JSON name. A nil value is sent as an explicit
+// null, which clears the field, while untouched fields are left out of the request entirely.
+type fieldSet map[string]interface{}
+
+func (fs fieldSet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}(fs))
+}
+
+// into copies every touched, non-null field onto an update input struct, so patches can share the validation
+// rules of the inputs they replace
+func (fs fieldSet) into(dest interface{}) {
+	set := map[string]interface{}{}
+	for k, v := range fs {
+		if v != nil {
+			set[k] = v
+		}
+	}
+	b, _ := json.Marshal(set)
+	json.Unmarshal(b, dest)
+}
+
+////////////////////////////////////////////////////////
+//                                                    //
+//                   Product Patch                    //
+//                                                    //
+////////////////////////////////////////////////////////
+
+// ProductPatch builds a partial product update that sends only the fields it touches. Unlike
+// ProductUpdateInput, it can set a field to its zero value, like a quantity of 0 or on_sale false, and can
+// clear optional fields.
+type ProductPatch struct {
+	fields fieldSet
+}
+
+// NewProductPatch returns a ProductPatch that touches no fields
+func NewProductPatch() *ProductPatch {
+	return &ProductPatch{fields: fieldSet{}}
+}
+
+func (p *ProductPatch) MarshalJSON() ([]byte, error) {
+	return p.fields.MarshalJSON()
+}
+
+func (p *ProductPatch) set(field string, value interface{}) *ProductPatch {
+	p.fields[field] = value
+	return p
+}
+
+func (p *ProductPatch) SetName(name string) *ProductPatch { return p.set("name", name) }
+
+func (p *ProductPatch) SetSubtitle(subtitle string) *ProductPatch { return p.set("subtitle", subtitle) }
+
+func (p *ProductPatch) ClearSubtitle() *ProductPatch { return p.set("subtitle", nil) }
+
+func (p *ProductPatch) SetDescription(description string) *ProductPatch {
+	return p.set("description", description)
+}
+
+func (p *ProductPatch) ClearDescription() *ProductPatch { return p.set("description", nil) }
+
+func (p *ProductPatch) SetSKU(sku string) *ProductPatch { return p.set("sku", sku) }
+
+func (p *ProductPatch) SetUPC(upc string) *ProductPatch { return p.set("upc", upc) }
+
+func (p *ProductPatch) ClearUPC() *ProductPatch { return p.set("upc", nil) }
+
+func (p *ProductPatch) SetManufacturer(manufacturer string) *ProductPatch {
+	return p.set("manufacturer", manufacturer)
+}
+
+func (p *ProductPatch) ClearManufacturer() *ProductPatch { return p.set("manufacturer", nil) }
+
+func (p *ProductPatch) SetBrand(brand string) *ProductPatch { return p.set("brand", brand) }
+
+func (p *ProductPatch) ClearBrand() *ProductPatch { return p.set("brand", nil) }
+
+func (p *ProductPatch) SetQuantity(quantity uint32) *ProductPatch { return p.set("quantity", quantity) }
+
+func (p *ProductPatch) SetTaxable(taxable bool) *ProductPatch { return p.set("taxable", taxable) }
+
+func (p *ProductPatch) SetPrice(price float32) *ProductPatch { return p.set("price", price) }
+
+func (p *ProductPatch) SetOnSale(onSale bool) *ProductPatch { return p.set("on_sale", onSale) }
+
+func (p *ProductPatch) SetSalePrice(salePrice float32) *ProductPatch {
+	return p.set("sale_price", salePrice)
+}
+
+func (p *ProductPatch) SetCost(cost float32) *ProductPatch { return p.set("cost", cost) }
+
+func (p *ProductPatch) SetProductWeight(v float32) *ProductPatch { return p.set("product_weight", v) }
+
+func (p *ProductPatch) SetProductHeight(v float32) *ProductPatch { return p.set("product_height", v) }
+
+func (p *ProductPatch) SetProductWidth(v float32) *ProductPatch { return p.set("product_width", v) }
+
+func (p *ProductPatch) SetProductLength(v float32) *ProductPatch { return p.set("product_length", v) }
+
+func (p *ProductPatch) SetPackageWeight(v float32) *ProductPatch { return p.set("package_weight", v) }
+
+func (p *ProductPatch) SetPackageHeight(v float32) *ProductPatch { return p.set("package_height", v) }
+
+func (p *ProductPatch) SetPackageWidth(v float32) *ProductPatch { return p.set("package_width", v) }
+
+func (p *ProductPatch) SetPackageLength(v float32) *ProductPatch { return p.set("package_length", v) }
+
+func (p *ProductPatch) SetQuantityPerPackage(v uint32) *ProductPatch {
+	return p.set("quantity_per_package", v)
+}
+
+// PatchProduct updates only the fields touched on p
+func (dc *V1Client) PatchProduct(sku string, p *ProductPatch) (*models.Product, error) {
+	if err := requireIdentifier("sku", sku); err != nil {
+		return nil, err
+	}
+	if err := dc.validate(p); err != nil {
+		return nil, err
+	}
+	return dc.updateProduct(sku, p)
+}
+
+////////////////////////////////////////////////////////
+//                                                    //
+//           Product Option and Value Patches         //
+//                                                    //
+////////////////////////////////////////////////////////
+
+// ProductOptionPatch builds a partial product option update that sends only the fields it touches
+type ProductOptionPatch struct {
+	fields fieldSet
+}
+
+// NewProductOptionPatch returns a ProductOptionPatch that touches no fields
+func NewProductOptionPatch() *ProductOptionPatch {
+	return &ProductOptionPatch{fields: fieldSet{}}
+}
+
+func (p *ProductOptionPatch) MarshalJSON() ([]byte, error) {
+	return p.fields.MarshalJSON()
+}
+
+func (p *ProductOptionPatch) SetName(name string) *ProductOptionPatch {
+	p.fields["name"] = name
+	return p
+}
+
+// PatchProductOption updates only the fields touched on p
+func (dc *V1Client) PatchProductOption(optionID uint64, p *ProductOptionPatch) (*models.ProductOption, error) {
+	return dc.updateProductOption(optionID, p)
+}
+
+// ProductOptionValuePatch builds a partial product option value update that sends only the fields it touches
+type ProductOptionValuePatch struct {
+	fields fieldSet
+}
+
+// NewProductOptionValuePatch returns a ProductOptionValuePatch that touches no fields
+func NewProductOptionValuePatch() *ProductOptionValuePatch {
+	return &ProductOptionValuePatch{fields: fieldSet{}}
+}
+
+func (p *ProductOptionValuePatch) MarshalJSON() ([]byte, error) {
+	return p.fields.MarshalJSON()
+}
+
+func (p *ProductOptionValuePatch) SetValue(value string) *ProductOptionValuePatch {
+	p.fields["value"] = value
+	return p
+}
+
+// PatchProductOptionValue updates only the fields touched on p
+func (dc *V1Client) PatchProductOptionValue(valueID uint64, p *ProductOptionValuePatch) (*models.ProductOptionValue, error) {
+	return dc.updateProductOptionValue(valueID, p)
+}
+
+////////////////////////////////////////////////////////
+//                                                    //
+//                   Discount Patch                   //
+//                                                    //
+////////////////////////////////////////////////////////
+
+// DiscountPatch builds a partial discount update that sends only the fields it touches. Unlike
+// DiscountUpdateInput, it can turn flags like requires_code off, and can clear the expiry date and code.
+type DiscountPatch struct {
+	fields fieldSet
+}
+
+// NewDiscountPatch returns a DiscountPatch that touches no fields
+func NewDiscountPatch() *DiscountPatch {
+	return &DiscountPatch{fields: fieldSet{}}
+}
+
+func (p *DiscountPatch) MarshalJSON() ([]byte, error) {
+	return p.fields.MarshalJSON()
+}
+
+func (p *DiscountPatch) set(field string, value interface{}) *DiscountPatch {
+	p.fields[field] = value
+	return p
+}
+
+func (p *DiscountPatch) SetName(name string) *DiscountPatch { return p.set("name", name) }
+
+func (p *DiscountPatch) SetDiscountType(discountType string) *DiscountPatch {
+	return p.set("discount_type", discountType)
+}
+
+func (p *DiscountPatch) SetAmount(amount float32) *DiscountPatch { return p.set("amount", amount) }
+
+func (p *DiscountPatch) SetStartsOn(startsOn time.Time) *DiscountPatch {
+	return p.set("starts_on", &models.Dairytime{Time: startsOn})
+}
+
+func (p *DiscountPatch) SetExpiresOn(expiresOn time.Time) *DiscountPatch {
+	return p.set("expires_on", &models.Dairytime{Time: expiresOn})
+}
+
+func (p *DiscountPatch) ClearExpiresOn() *DiscountPatch { return p.set("expires_on", nil) }
+
+func (p *DiscountPatch) SetRequiresCode(requiresCode bool) *DiscountPatch {
+	return p.set("requires_code", requiresCode)
+}
+
+func (p *DiscountPatch) SetCode(code string) *DiscountPatch { return p.set("code", code) }
+
+func (p *DiscountPatch) ClearCode() *DiscountPatch { return p.set("code", nil) }
+
+func (p *DiscountPatch) SetLimitedUse(limitedUse bool) *DiscountPatch {
+	return p.set("limited_use", limitedUse)
+}
+
+func (p *DiscountPatch) SetNumberOfUses(numberOfUses uint64) *DiscountPatch {
+	return p.set("number_of_uses", numberOfUses)
+}
+
+func (p *DiscountPatch) SetLoginRequired(loginRequired bool) *DiscountPatch {
+	return p.set("login_required", loginRequired)
+}
+
+// PatchDiscount updates only the fields touched on p
+func (dc *V1Client) PatchDiscount(discountID uint64, p *DiscountPatch) (*models.Discount, error) {
+	if err := dc.validate(p); err != nil {
+		return nil, err
+	}
+	return dc.updateDiscount(discountID, p)
+}
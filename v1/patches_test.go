@@ -0,0 +1,170 @@
+package dairyclient_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dairycart/dairyclient/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchMarshaling(t *testing.T) {
+	starts := time.Date(2017, 12, 10, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		patch    json.Marshaler
+		expected string
+	}{
+		{
+			name:     "untouched product patch",
+			patch:    dairyclient.NewProductPatch(),
+			expected: `{}`,
+		},
+		{
+			name:     "product patch with zero values and nulls",
+			patch:    dairyclient.NewProductPatch().SetPrice(12.5).SetQuantity(0).SetOnSale(false).ClearUPC(),
+			expected: `{"on_sale": false, "price": 12.5, "quantity": 0, "upc": null}`,
+		},
+		{
+			name:     "later calls replace earlier ones",
+			patch:    dairyclient.NewProductPatch().SetBrand("brand").ClearBrand(),
+			expected: `{"brand": null}`,
+		},
+		{
+			name:     "product option patch",
+			patch:    dairyclient.NewProductOptionPatch().SetName("color"),
+			expected: `{"name": "color"}`,
+		},
+		{
+			name:     "product option value patch",
+			patch:    dairyclient.NewProductOptionValuePatch().SetValue("red"),
+			expected: `{"value": "red"}`,
+		},
+		{
+			name:     "discount patch",
+			patch:    dairyclient.NewDiscountPatch().SetRequiresCode(false).ClearCode().SetStartsOn(starts).ClearExpiresOn(),
+			expected: `{"code": null, "expires_on": null, "requires_code": false, "starts_on": "2017-12-10T00:00:00Z"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := json.Marshal(tc.patch)
+			require.Nil(t, err)
+			assert.Equal(t, minifyJSON(t, tc.expected), string(actual))
+		})
+	}
+}
+
+func TestPatchValidation(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    interface{}
+		expected []string
+	}{
+		{
+			name:  "valid product patch",
+			input: dairyclient.NewProductPatch().SetPrice(0).ClearUPC(),
+		},
+		{
+			name:     "invalid product patch",
+			input:    dairyclient.NewProductPatch().SetSKU(" ").SetPrice(10).SetSalePrice(12).SetProductWidth(-1),
+			expected: []string{"sku", "sale_price", "product_width"},
+		},
+		{
+			name:  "valid discount patch",
+			input: dairyclient.NewDiscountPatch().SetRequiresCode(false).ClearCode(),
+		},
+		{
+			name: "invalid discount patch",
+			input: dairyclient.NewDiscountPatch().
+				SetName("").
+				SetDiscountType("percentage").
+				SetAmount(150).
+				SetRequiresCode(true).
+				ClearCode(),
+			expected: []string{"name", "amount", "code"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := dairyclient.Validate(tc.input)
+			assert.Equal(t, tc.expected, validationFields(t, err))
+		})
+	}
+}
+
+func TestPatchProduct(t *testing.T) {
+	handlers := map[string]http.HandlerFunc{
+		"/v1/product/sku": generatePatchHandler(t, `{"quantity": 0, "upc": null}`, loadExampleResponse(t, "updated_product"), http.StatusOK),
+	}
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("normal usage", func(*testing.T) {
+		actual, err := c.PatchProduct(exampleSKU, dairyclient.NewProductPatch().SetQuantity(0).ClearUPC())
+		assert.Nil(t, err)
+		assert.Equal(t, "sku", actual.SKU)
+	})
+
+	t.Run("with empty sku", func(*testing.T) {
+		_, err := c.PatchProduct("", dairyclient.NewProductPatch())
+		assert.NotNil(t, err)
+	})
+
+	t.Run("with invalid patch", func(*testing.T) {
+		_, err := c.PatchProduct(exampleSKU, dairyclient.NewProductPatch().SetPrice(-1))
+		assert.IsType(t, &dairyclient.ValidationError{}, err)
+	})
+}
+
+func TestPatchProductOption(t *testing.T) {
+	handlers := map[string]http.HandlerFunc{
+		"/v1/product_options/1": generatePatchHandler(t, `{"name": "color"}`, loadExampleResponse(t, "updated_product_option"), http.StatusOK),
+	}
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	_, err := c.PatchProductOption(1, dairyclient.NewProductOptionPatch().SetName("color"))
+	assert.Nil(t, err)
+}
+
+func TestPatchProductOptionValue(t *testing.T) {
+	handlers := map[string]http.HandlerFunc{
+		"/v1/product_option_values/1": generatePatchHandler(t, `{"value": "red"}`, loadExampleResponse(t, "created_product_option_value"), http.StatusOK),
+	}
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	_, err := c.PatchProductOptionValue(1, dairyclient.NewProductOptionValuePatch().SetValue("red"))
+	assert.Nil(t, err)
+}
+
+func TestPatchDiscount(t *testing.T) {
+	handlers := map[string]http.HandlerFunc{
+		"/v1/discount/1": generatePatchHandler(t, `{"code": null, "requires_code": false}`, loadExampleResponse(t, "updated_discount"), http.StatusOK),
+	}
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("normal usage", func(*testing.T) {
+		_, err := c.PatchDiscount(1, dairyclient.NewDiscountPatch().SetRequiresCode(false).ClearCode())
+		assert.Nil(t, err)
+	})
+
+	t.Run("with invalid patch", func(*testing.T) {
+		_, err := c.PatchDiscount(1, dairyclient.NewDiscountPatch().SetAmount(-1))
+		assert.IsType(t, &dairyclient.ValidationError{}, err)
+	})
+}
@@ -0,0 +1,226 @@
+// Package pricing computes product, line and cart prices locally from the models the client returns,
+// so that a storefront can preview what the server would charge without making a request.
+package pricing
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/dairycart/dairymodels/v1"
+)
+
+// These are the discount types Dairycart understands
+const (
+	PercentageDiscount = "percentage"
+	FlatAmountDiscount = "flat_amount"
+)
+
+// These are the reasons a discount may not apply to a cart
+const (
+	ReasonArchived       = "archived"
+	ReasonNotStarted     = "not_started"
+	ReasonExpired        = "expired"
+	ReasonExhausted      = "exhausted"
+	ReasonLoginRequired  = "login_required"
+	ReasonCodeRequired   = "code_required"
+	ReasonUnknownType    = "unknown_type"
+	ReasonBetterDiscount = "better_discount_applied"
+)
+
+// IneligibilityError explains why a discount does not apply
+type IneligibilityError struct {
+	DiscountID uint64
+	Reason     string
+}
+
+func (ie *IneligibilityError) Error() string {
+	switch ie.Reason {
+	case ReasonArchived:
+		return fmt.Sprintf("discount %d has been archived", ie.DiscountID)
+	case ReasonNotStarted:
+		return fmt.Sprintf("discount %d is not active yet", ie.DiscountID)
+	case ReasonExpired:
+		return fmt.Sprintf("discount %d has expired", ie.DiscountID)
+	case ReasonExhausted:
+		return fmt.Sprintf("discount %d has no uses remaining", ie.DiscountID)
+	case ReasonLoginRequired:
+		return fmt.Sprintf("discount %d requires the shopper to be logged in", ie.DiscountID)
+	case ReasonCodeRequired:
+		return fmt.Sprintf("discount %d requires a code that was not provided", ie.DiscountID)
+	case ReasonUnknownType:
+		return fmt.Sprintf("discount %d has an unrecognized type", ie.DiscountID)
+	case ReasonBetterDiscount:
+		return fmt.Sprintf("discount %d was superseded by a larger discount", ie.DiscountID)
+	}
+	return fmt.Sprintf("discount %d does not apply: %s", ie.DiscountID, ie.Reason)
+}
+
+// Shopper describes the circumstances a discount is being checked under
+type Shopper struct {
+	LoggedIn bool
+	Codes    []string
+	At       time.Time
+}
+
+func (s Shopper) hasCode(code string) bool {
+	for _, c := range s.Codes {
+		if strings.EqualFold(c, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// Item is a quantity of a product in a cart
+type Item struct {
+	Product  models.Product
+	Quantity uint32
+}
+
+// Line is the computed price of an Item
+type Line struct {
+	SKU       string
+	Quantity  uint32
+	UnitPrice float64
+	Total     float64
+	Taxable   bool
+}
+
+// Totals is the computed price of a set of Items
+type Totals struct {
+	Lines           []Line
+	Subtotal        float64
+	TaxableSubtotal float64
+	Discount        float64
+	Total           float64
+	Applied         *models.Discount
+	Rejected        []IneligibilityError
+}
+
+func roundToCents(f float64) float64 {
+	return math.Round(f*100) / 100
+}
+
+// UnitPrice returns the price a product sells for, accounting for sales
+func UnitPrice(p models.Product) float64 {
+	if p.OnSale {
+		return roundToCents(float64(p.SalePrice))
+	}
+	return roundToCents(float64(p.Price))
+}
+
+// LineTotal computes the price of a quantity of a given product
+func LineTotal(i Item) Line {
+	up := UnitPrice(i.Product)
+	return Line{
+		SKU:       i.Product.SKU,
+		Quantity:  i.Quantity,
+		UnitPrice: up,
+		Total:     roundToCents(up * float64(i.Quantity)),
+		Taxable:   i.Product.Taxable,
+	}
+}
+
+// CheckActive returns nil if a discount is usable at a given time, or an *IneligibilityError explaining why it
+// is not. Limited use discounts are considered exhausted once their NumberOfUses reaches zero.
+func CheckActive(d models.Discount, at time.Time) error {
+	var reason string
+	switch {
+	case d.ArchivedOn != nil:
+		reason = ReasonArchived
+	case at.Before(d.StartsOn):
+		reason = ReasonNotStarted
+	case d.ExpiresOn != nil && !d.ExpiresOn.Time.IsZero() && !at.Before(d.ExpiresOn.Time):
+		reason = ReasonExpired
+	case d.LimitedUse && d.NumberOfUses == 0:
+		reason = ReasonExhausted
+	default:
+		return nil
+	}
+
+	return &IneligibilityError{DiscountID: d.ID, Reason: reason}
+}
+
+// CheckEligibility returns nil if a discount applies for a given shopper, or an *IneligibilityError explaining why it
+// does not. In addition to the checks CheckActive makes, it enforces login and code requirements.
+func CheckEligibility(d models.Discount, s Shopper) error {
+	at := s.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	if err := CheckActive(d, at); err != nil {
+		return err
+	}
+
+	var reason string
+	switch {
+	case d.LoginRequired && !s.LoggedIn:
+		reason = ReasonLoginRequired
+	case d.RequiresCode && !s.hasCode(d.Code):
+		reason = ReasonCodeRequired
+	case d.DiscountType != PercentageDiscount && d.DiscountType != FlatAmountDiscount:
+		reason = ReasonUnknownType
+	default:
+		return nil
+	}
+
+	return &IneligibilityError{DiscountID: d.ID, Reason: reason}
+}
+
+// DiscountAmount returns how much a discount takes off of a given subtotal, never exceeding the subtotal itself
+func DiscountAmount(d models.Discount, subtotal float64) float64 {
+	var amount float64
+	switch d.DiscountType {
+	case PercentageDiscount:
+		amount = subtotal * float64(d.Amount) / 100
+	case FlatAmountDiscount:
+		amount = float64(d.Amount)
+	}
+	return roundToCents(math.Max(0, math.Min(amount, subtotal)))
+}
+
+// CartTotal computes line totals and the cart total for a set of items. Of the discounts provided, the single
+// eligible discount that saves the shopper the most is applied; every other discount is listed in Rejected
+// along with the reason it was not used.
+func CartTotal(items []Item, discounts []models.Discount, s Shopper) Totals {
+	t := Totals{}
+	for _, i := range items {
+		l := LineTotal(i)
+		t.Lines = append(t.Lines, l)
+		t.Subtotal += l.Total
+		if l.Taxable {
+			t.TaxableSubtotal += l.Total
+		}
+	}
+	t.Subtotal = roundToCents(t.Subtotal)
+	t.TaxableSubtotal = roundToCents(t.TaxableSubtotal)
+
+	best := -1
+	for idx, d := range discounts {
+		if err := CheckEligibility(d, s); err != nil {
+			t.Rejected = append(t.Rejected, *err.(*IneligibilityError))
+			continue
+		}
+
+		amount := DiscountAmount(d, t.Subtotal)
+		if best < 0 || amount > t.Discount {
+			if best >= 0 {
+				t.Rejected = append(t.Rejected, IneligibilityError{DiscountID: discounts[best].ID, Reason: ReasonBetterDiscount})
+			}
+			best = idx
+			t.Discount = amount
+		} else {
+			t.Rejected = append(t.Rejected, IneligibilityError{DiscountID: d.ID, Reason: ReasonBetterDiscount})
+		}
+	}
+
+	if best >= 0 {
+		applied := discounts[best]
+		t.Applied = &applied
+	}
+	t.Total = roundToCents(t.Subtotal - t.Discount)
+	return t
+}
@@ -0,0 +1,175 @@
+package pricing_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dairycart/dairyclient/v1/pricing"
+	"github.com/dairycart/dairymodels/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildTestTime(t *testing.T) time.Time {
+	t.Helper()
+	xt, err := time.Parse(time.RFC3339, "2017-12-10T15:58:43Z")
+	require.NoError(t, err)
+	return xt
+}
+
+func buildTestDiscount(t *testing.T) models.Discount {
+	t.Helper()
+	return models.Discount{
+		ID:           1,
+		Name:         "10 percent off",
+		DiscountType: pricing.PercentageDiscount,
+		Amount:       10,
+		StartsOn:     buildTestTime(t).Add(-time.Hour),
+		ExpiresOn:    &models.Dairytime{Time: buildTestTime(t).Add(time.Hour)},
+	}
+}
+
+func TestUnitPrice(t *testing.T) {
+	t.Run("normal usage", func(*testing.T) {
+		assert.Equal(t, 20.0, pricing.UnitPrice(models.Product{Price: 20, SalePrice: 10}))
+	})
+
+	t.Run("on sale", func(*testing.T) {
+		assert.Equal(t, 10.0, pricing.UnitPrice(models.Product{Price: 20, OnSale: true, SalePrice: 10}))
+	})
+}
+
+func TestLineTotal(t *testing.T) {
+	expected := pricing.Line{
+		SKU:       "sku",
+		Quantity:  3,
+		UnitPrice: 19.99,
+		Total:     59.97,
+		Taxable:   true,
+	}
+	actual := pricing.LineTotal(pricing.Item{
+		Product:  models.Product{SKU: "sku", Price: 19.99, Taxable: true},
+		Quantity: 3,
+	})
+	assert.Equal(t, expected, actual)
+}
+
+func TestCheckEligibility(t *testing.T) {
+	shopper := pricing.Shopper{At: buildTestTime(t)}
+
+	testCases := map[string]struct {
+		modify   func(*models.Discount)
+		shopper  pricing.Shopper
+		expected string
+	}{
+		"eligible": {
+			modify:  func(d *models.Discount) {},
+			shopper: shopper,
+		},
+		"archived": {
+			modify:   func(d *models.Discount) { d.ArchivedOn = &models.Dairytime{Time: buildTestTime(t)} },
+			shopper:  shopper,
+			expected: pricing.ReasonArchived,
+		},
+		"not started": {
+			modify:   func(d *models.Discount) { d.StartsOn = buildTestTime(t).Add(time.Minute) },
+			shopper:  shopper,
+			expected: pricing.ReasonNotStarted,
+		},
+		"expired": {
+			modify:   func(d *models.Discount) { d.ExpiresOn = &models.Dairytime{Time: buildTestTime(t)} },
+			shopper:  shopper,
+			expected: pricing.ReasonExpired,
+		},
+		"exhausted": {
+			modify:   func(d *models.Discount) { d.LimitedUse = true },
+			shopper:  shopper,
+			expected: pricing.ReasonExhausted,
+		},
+		"login required": {
+			modify:   func(d *models.Discount) { d.LoginRequired = true },
+			shopper:  shopper,
+			expected: pricing.ReasonLoginRequired,
+		},
+		"code required": {
+			modify:   func(d *models.Discount) { d.RequiresCode = true; d.Code = "TENOFF" },
+			shopper:  shopper,
+			expected: pricing.ReasonCodeRequired,
+		},
+		"code provided": {
+			modify:  func(d *models.Discount) { d.RequiresCode = true; d.Code = "TENOFF" },
+			shopper: pricing.Shopper{At: buildTestTime(t), Codes: []string{"TENOFF"}},
+		},
+		"unknown type": {
+			modify:   func(d *models.Discount) { d.DiscountType = "buy_one_get_one" },
+			shopper:  shopper,
+			expected: pricing.ReasonUnknownType,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(*testing.T) {
+			d := buildTestDiscount(t)
+			tc.modify(&d)
+
+			err := pricing.CheckEligibility(d, tc.shopper)
+			if tc.expected == "" {
+				assert.Nil(t, err)
+				return
+			}
+			require.NotNil(t, err)
+			assert.Equal(t, tc.expected, err.(*pricing.IneligibilityError).Reason)
+			assert.NotEmpty(t, err.Error())
+		})
+	}
+}
+
+func TestDiscountAmount(t *testing.T) {
+	t.Run("percentage", func(*testing.T) {
+		d := models.Discount{DiscountType: pricing.PercentageDiscount, Amount: 10}
+		assert.Equal(t, 6.0, pricing.DiscountAmount(d, 60))
+	})
+
+	t.Run("flat amount", func(*testing.T) {
+		d := models.Discount{DiscountType: pricing.FlatAmountDiscount, Amount: 5}
+		assert.Equal(t, 5.0, pricing.DiscountAmount(d, 60))
+	})
+
+	t.Run("never exceeds subtotal", func(*testing.T) {
+		d := models.Discount{DiscountType: pricing.FlatAmountDiscount, Amount: 100}
+		assert.Equal(t, 60.0, pricing.DiscountAmount(d, 60))
+	})
+}
+
+func TestCartTotal(t *testing.T) {
+	items := []pricing.Item{
+		{Product: models.Product{SKU: "shirt", Price: 20, Taxable: true}, Quantity: 2},
+		{Product: models.Product{SKU: "sticker", Price: 5, OnSale: true, SalePrice: 2.5}, Quantity: 4},
+	}
+
+	percentOff := buildTestDiscount(t)
+	flatOff := buildTestDiscount(t)
+	flatOff.ID = 2
+	flatOff.DiscountType = pricing.FlatAmountDiscount
+	flatOff.Amount = 10
+	expired := buildTestDiscount(t)
+	expired.ID = 3
+	expired.ExpiresOn = &models.Dairytime{Time: buildTestTime(t).Add(-time.Minute)}
+
+	actual := pricing.CartTotal(items, []models.Discount{percentOff, flatOff, expired}, pricing.Shopper{At: buildTestTime(t)})
+
+	assert.Len(t, actual.Lines, 2)
+	assert.Equal(t, 50.0, actual.Subtotal)
+	assert.Equal(t, 40.0, actual.TaxableSubtotal)
+	assert.Equal(t, 10.0, actual.Discount)
+	assert.Equal(t, 40.0, actual.Total)
+	require.NotNil(t, actual.Applied)
+	assert.Equal(t, uint64(2), actual.Applied.ID)
+
+	expectedRejections := []pricing.IneligibilityError{
+		{DiscountID: 1, Reason: pricing.ReasonBetterDiscount},
+		{DiscountID: 3, Reason: pricing.ReasonExpired},
+	}
+	assert.Equal(t, expectedRejections, actual.Rejected)
+}
@@ -1,7 +1,12 @@
 package dairyclient
 
 import (
+	"strings"
+	"time"
+
 	"github.com/dairycart/dairymodels/v1"
+
+	"github.com/pkg/errors"
 )
 
 ////////////////////////////////////////////////////////
@@ -11,26 +16,24 @@ import (
 ////////////////////////////////////////////////////////
 
 func (dc *V1Client) ProductExists(sku string) (bool, error) {
+	if err := requireIdentifier("sku", sku); err != nil {
+		return false, err
+	}
 	u := dc.buildURL(nil, "product", sku)
 	return dc.exists(u)
 }
 
 func (dc *V1Client) GetProduct(sku string) (*models.Product, error) {
-	u := dc.buildURL(nil, "product", sku)
-	p := models.Product{}
-
-	err := dc.get(u, &p)
-	if err != nil {
+	if err := requireIdentifier("sku", sku); err != nil {
 		return nil, err
 	}
-	return &p, nil
+	u := dc.buildURL(nil, "product", sku)
+	return get[models.Product](dc, u)
 }
 
 func (dc *V1Client) GetProducts(queryFilter map[string]string) ([]models.Product, error) {
 	u := dc.buildURL(queryFilter, "products")
-	pl := &models.ProductListResponse{}
-
-	err := dc.get(u, &pl)
+	pl, err := get[models.ProductListResponse](dc, u)
 	if err != nil {
 		return nil, err
 	}
@@ -38,35 +41,68 @@ func (dc *V1Client) GetProducts(queryFilter map[string]string) ([]models.Product
 	return pl.Products, nil
 }
 
-func (dc *V1Client) CreateProduct(np models.ProductCreationInput) (*models.Product, error) {
-	p := models.Product{}
-	u := dc.buildURL(nil, "product")
+// GetAllProducts pages through the product list until every active product has been retrieved
+func (dc *V1Client) GetAllProducts() ([]models.Product, error) {
+	return dc.getAllProducts(nil)
+}
 
-	err := dc.post(u, np, &p)
+// GetArchivedProducts retrieves the products on a page of results that have been archived
+func (dc *V1Client) GetArchivedProducts(queryFilter map[string]string) ([]models.Product, error) {
+	products, err := dc.GetProducts(IncludeArchived(queryFilter))
 	if err != nil {
 		return nil, err
 	}
 
-	return &p, nil
+	archived := []models.Product{}
+	for _, p := range products {
+		if p.ArchivedOn != nil {
+			archived = append(archived, p)
+		}
+	}
+	return archived, nil
 }
 
-func (dc *V1Client) UpdateProduct(sku string, up models.ProductUpdateInput) (*models.Product, error) {
-	p := models.Product{}
-	u := dc.buildURL(nil, "product", sku)
+func (dc *V1Client) CreateProduct(np models.ProductCreationInput) (*models.Product, error) {
+	if err := dc.validate(np); err != nil {
+		return nil, err
+	}
+	u := dc.buildURL(nil, "product")
+	return post[models.Product](dc, u, np)
+}
 
-	err := dc.patch(u, up, &p)
-	if err != nil {
+func (dc *V1Client) UpdateProduct(sku string, up models.ProductUpdateInput) (*models.Product, error) {
+	if err := requireIdentifier("sku", sku); err != nil {
+		return nil, err
+	}
+	if err := dc.validate(up); err != nil {
 		return nil, err
 	}
+	return dc.updateProduct(sku, up)
+}
 
-	return &p, nil
+// updateProduct sends an already validated product update, either a ProductUpdateInput or a ProductPatch
+func (dc *V1Client) updateProduct(sku string, body interface{}) (*models.Product, error) {
+	u := dc.buildURL(nil, "product", sku)
+	return patch[models.Product](dc, u, body)
 }
 
 func (dc *V1Client) DeleteProduct(sku string) error {
+	if err := requireIdentifier("sku", sku); err != nil {
+		return err
+	}
 	u := dc.buildURL(nil, "product", sku)
 	return dc.delete(u)
 }
 
+// RestoreProduct undoes the archival of a product
+func (dc *V1Client) RestoreProduct(sku string) (*models.Product, error) {
+	if err := requireIdentifier("sku", sku); err != nil {
+		return nil, err
+	}
+	u := dc.buildURL(nil, "product", sku, "restore")
+	return post[models.Product](dc, u, struct{}{})
+}
+
 ////////////////////////////////////////////////////////
 //                                                    //
 //              Product Root Functions                //
@@ -76,21 +112,12 @@ func (dc *V1Client) DeleteProduct(sku string) error {
 func (dc *V1Client) GetProductRoot(rootID uint64) (*models.ProductRoot, error) {
 	rootIDString := convertIDToString(rootID)
 	u := dc.buildURL(nil, "product_root", rootIDString)
-
-	r := models.ProductRoot{}
-	err := dc.get(u, &r)
-	if err != nil {
-		return nil, err
-	}
-
-	return &r, nil
+	return get[models.ProductRoot](dc, u)
 }
 
 func (dc *V1Client) GetProductRoots(queryFilter map[string]string) ([]models.ProductRoot, error) {
 	u := dc.buildURL(queryFilter, "product_roots")
-
-	rl := &models.ProductRootListResponse{}
-	err := dc.get(u, &rl)
+	rl, err := get[models.ProductRootListResponse](dc, u)
 	if err != nil {
 		return nil, err
 	}
@@ -98,12 +125,195 @@ func (dc *V1Client) GetProductRoots(queryFilter map[string]string) ([]models.Pro
 	return rl.ProductRoots, nil
 }
 
+// GetAllProductRoots pages through the product root list until every product root has been retrieved
+func (dc *V1Client) GetAllProductRoots() ([]models.ProductRoot, error) {
+	return getAll(func(page uint64) ([]models.ProductRoot, uint64, error) {
+		u := dc.buildURL(map[string]string{"page": convertIDToString(page)}, "product_roots")
+		rl, err := get[models.ProductRootListResponse](dc, u)
+		if err != nil {
+			return nil, 0, err
+		}
+		return rl.ProductRoots, uint64(rl.Count), nil
+	})
+}
+
 func (dc *V1Client) DeleteProductRoot(rootID uint64) error {
 	rootIDString := convertIDToString(rootID)
 	u := dc.buildURL(nil, "product_root", rootIDString)
 	return dc.delete(u)
 }
 
+// RestoreProductRoot undoes the archival of a product root
+func (dc *V1Client) RestoreProductRoot(rootID uint64) (*models.ProductRoot, error) {
+	rootIDString := convertIDToString(rootID)
+	u := dc.buildURL(nil, "product_root", rootIDString, "restore")
+	return post[models.ProductRoot](dc, u, struct{}{})
+}
+
+// ProductRootCreationInput is the body sent when creating a product root. Its fields are shared by every
+// product variant created under the root.
+type ProductRootCreationInput struct {
+	Name               string     `json:"name,omitempty"`
+	Subtitle           string     `json:"subtitle,omitempty"`
+	Description        string     `json:"description,omitempty"`
+	SKUPrefix          string     `json:"sku_prefix,omitempty"`
+	Manufacturer       string     `json:"manufacturer,omitempty"`
+	Brand              string     `json:"brand,omitempty"`
+	Taxable            bool       `json:"taxable,omitempty"`
+	Cost               float32    `json:"cost,omitempty"`
+	ProductWeight      float32    `json:"product_weight,omitempty"`
+	ProductHeight      float32    `json:"product_height,omitempty"`
+	ProductWidth       float32    `json:"product_width,omitempty"`
+	ProductLength      float32    `json:"product_length,omitempty"`
+	PackageWeight      float32    `json:"package_weight,omitempty"`
+	PackageHeight      float32    `json:"package_height,omitempty"`
+	PackageWidth       float32    `json:"package_width,omitempty"`
+	PackageLength      float32    `json:"package_length,omitempty"`
+	QuantityPerPackage uint32     `json:"quantity_per_package,omitempty"`
+	AvailableOn        *time.Time `json:"available_on,omitempty"`
+}
+
+// ProductRootUpdateInput is the body sent when updating a product root. Zero values are left unchanged, so Taxable
+// is a pointer in order to be able to turn it off.
+type ProductRootUpdateInput struct {
+	Name               string     `json:"name,omitempty"`
+	Subtitle           string     `json:"subtitle,omitempty"`
+	Description        string     `json:"description,omitempty"`
+	SKUPrefix          string     `json:"sku_prefix,omitempty"`
+	Manufacturer       string     `json:"manufacturer,omitempty"`
+	Brand              string     `json:"brand,omitempty"`
+	Taxable            *bool      `json:"taxable,omitempty"`
+	Cost               float32    `json:"cost,omitempty"`
+	ProductWeight      float32    `json:"product_weight,omitempty"`
+	ProductHeight      float32    `json:"product_height,omitempty"`
+	ProductWidth       float32    `json:"product_width,omitempty"`
+	ProductLength      float32    `json:"product_length,omitempty"`
+	PackageWeight      float32    `json:"package_weight,omitempty"`
+	PackageHeight      float32    `json:"package_height,omitempty"`
+	PackageWidth       float32    `json:"package_width,omitempty"`
+	PackageLength      float32    `json:"package_length,omitempty"`
+	QuantityPerPackage uint32     `json:"quantity_per_package,omitempty"`
+	AvailableOn        *time.Time `json:"available_on,omitempty"`
+}
+
+func (dc *V1Client) CreateProductRoot(nr ProductRootCreationInput) (*models.ProductRoot, error) {
+	u := dc.buildURL(nil, "product_root")
+	return post[models.ProductRoot](dc, u, nr)
+}
+
+func (dc *V1Client) UpdateProductRoot(rootID uint64, ur ProductRootUpdateInput) (*models.ProductRoot, error) {
+	rootIDString := convertIDToString(rootID)
+	u := dc.buildURL(nil, "product_root", rootIDString)
+	return patch[models.ProductRoot](dc, u, ur)
+}
+
+func (dc *V1Client) CreateProductRootVariant(rootID uint64, np models.ProductCreationInput) (*models.Product, error) {
+	if err := dc.validate(np); err != nil {
+		return nil, err
+	}
+	rootIDString := convertIDToString(rootID)
+	u := dc.buildURL(nil, "product_root", rootIDString, "product")
+	return post[models.Product](dc, u, np)
+}
+
+func variantSKUPart(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), "-"))
+}
+
+// GenerateVariants returns one ProductCreationInput per combination of option values, in the order the options
+// and their values are provided. Each variant starts as a copy of base, inherits any shared field from the root
+// that base leaves empty, and gets a SKU built from the root's SKU prefix and the variant's values, like
+// `t-shirt-small-red`. Without a SKU prefix, the SKU is just the values, like `small-red`. A root with no options
+// yields a single variant with the SKU prefix as its SKU. Variants of a taxable root are always taxable, since an
+// unset Taxable on base can't be told apart from one set to false.
+func GenerateVariants(root ProductRootCreationInput, options []models.ProductOption, base models.ProductCreationInput) []models.ProductCreationInput {
+	combinations := [][]string{{}}
+	for _, o := range options {
+		if len(o.Values) == 0 {
+			continue
+		}
+
+		var next [][]string
+		for _, c := range combinations {
+			for _, v := range o.Values {
+				combo := append(append([]string{}, c...), variantSKUPart(v.Value))
+				next = append(next, combo)
+			}
+		}
+		combinations = next
+	}
+
+	inherit := func(s *string, from string) {
+		if *s == "" {
+			*s = from
+		}
+	}
+	inheritFloat := func(f *float32, from float32) {
+		if *f == 0 {
+			*f = from
+		}
+	}
+
+	variants := make([]models.ProductCreationInput, 0, len(combinations))
+	for _, c := range combinations {
+		v := base
+		inherit(&v.Name, root.Name)
+		inherit(&v.Subtitle, root.Subtitle)
+		inherit(&v.Description, root.Description)
+		inherit(&v.Manufacturer, root.Manufacturer)
+		inherit(&v.Brand, root.Brand)
+		inheritFloat(&v.Cost, root.Cost)
+		inheritFloat(&v.ProductWeight, root.ProductWeight)
+		inheritFloat(&v.ProductHeight, root.ProductHeight)
+		inheritFloat(&v.ProductWidth, root.ProductWidth)
+		inheritFloat(&v.ProductLength, root.ProductLength)
+		inheritFloat(&v.PackageWeight, root.PackageWeight)
+		inheritFloat(&v.PackageHeight, root.PackageHeight)
+		inheritFloat(&v.PackageWidth, root.PackageWidth)
+		inheritFloat(&v.PackageLength, root.PackageLength)
+		if v.QuantityPerPackage == 0 {
+			v.QuantityPerPackage = root.QuantityPerPackage
+		}
+		v.Taxable = v.Taxable || root.Taxable
+
+		parts := c
+		if root.SKUPrefix != "" {
+			parts = append([]string{root.SKUPrefix}, c...)
+		}
+		v.SKU = strings.Join(parts, "-")
+		variants = append(variants, v)
+	}
+	return variants
+}
+
+// CreateProductRootWithVariants creates a product root, creates each of the given options under it, and then
+// creates a product for every combination of option values (see GenerateVariants). It returns the root as the
+// server reports it once everything has been created. If any step fails, the entities created so far are left
+// in place and the returned error says which step failed.
+func (dc *V1Client) CreateProductRootWithVariants(nr ProductRootCreationInput, options []models.ProductOptionCreationInput, base models.ProductCreationInput) (*models.ProductRoot, error) {
+	root, err := dc.CreateProductRoot(nr)
+	if err != nil {
+		return nil, errors.Wrap(err, "encountered error creating product root")
+	}
+
+	createdOptions := []models.ProductOption{}
+	for _, no := range options {
+		o, err := dc.CreateProductOption(root.ID, no)
+		if err != nil {
+			return nil, errors.Wrapf(err, "encountered error creating option %q", no.Name)
+		}
+		createdOptions = append(createdOptions, *o)
+	}
+
+	for _, np := range GenerateVariants(nr, createdOptions, base) {
+		if _, err := dc.CreateProductRootVariant(root.ID, np); err != nil {
+			return nil, errors.Wrapf(err, "encountered error creating variant %q", np.SKU)
+		}
+	}
+
+	return dc.GetProductRoot(root.ID)
+}
+
 ////////////////////////////////////////////////////////
 //                                                    //
 //             Product Option Functions               //
@@ -113,9 +323,7 @@ func (dc *V1Client) DeleteProductRoot(rootID uint64) error {
 func (dc *V1Client) GetProductOptions(productID uint64, queryFilter map[string]string) ([]models.ProductOption, error) {
 	productIDString := convertIDToString(productID)
 	u := dc.buildURL(queryFilter, "product", productIDString, "options")
-	ol := &models.ProductOptionListResponse{}
-
-	err := dc.get(u, &ol)
+	ol, err := get[models.ProductOptionListResponse](dc, u)
 	if err != nil {
 		return nil, err
 	}
@@ -124,29 +332,23 @@ func (dc *V1Client) GetProductOptions(productID uint64, queryFilter map[string]s
 }
 
 func (dc *V1Client) CreateProductOption(productRootID uint64, no models.ProductOptionCreationInput) (*models.ProductOption, error) {
-	productRootIDString := convertIDToString(productRootID)
-	o := models.ProductOption{}
-	u := dc.buildURL(nil, "product", productRootIDString, "options")
-
-	err := dc.post(u, no, &o)
-	if err != nil {
+	if err := dc.validate(no); err != nil {
 		return nil, err
 	}
-
-	return &o, nil
+	productRootIDString := convertIDToString(productRootID)
+	u := dc.buildURL(nil, "product", productRootIDString, "options")
+	return post[models.ProductOption](dc, u, no)
 }
 
 func (dc *V1Client) UpdateProductOption(optionID uint64, uo models.ProductOptionUpdateInput) (*models.ProductOption, error) {
+	return dc.updateProductOption(optionID, uo)
+}
+
+// updateProductOption sends a product option update, either a ProductOptionUpdateInput or a ProductOptionPatch
+func (dc *V1Client) updateProductOption(optionID uint64, body interface{}) (*models.ProductOption, error) {
 	optionIDString := convertIDToString(optionID)
 	u := dc.buildURL(nil, "product_options", optionIDString)
-	o := models.ProductOption{}
-
-	err := dc.patch(u, uo, &o)
-	if err != nil {
-		return nil, err
-	}
-
-	return &o, nil
+	return patch[models.ProductOption](dc, u, body)
 }
 
 func (dc *V1Client) DeleteProductOption(optionID uint64) error {
@@ -164,27 +366,19 @@ func (dc *V1Client) DeleteProductOption(optionID uint64) error {
 func (dc *V1Client) CreateProductOptionValue(optionID uint64, nv models.ProductOptionValueCreationInput) (*models.ProductOptionValue, error) {
 	optionIDString := convertIDToString(optionID)
 	u := dc.buildURL(nil, "product_options", optionIDString, "value")
-	v := models.ProductOptionValue{}
-
-	err := dc.post(u, nv, &v)
-	if err != nil {
-		return nil, err
-	}
-
-	return &v, nil
+	return post[models.ProductOptionValue](dc, u, nv)
 }
 
 func (dc *V1Client) UpdateProductOptionValue(valueID uint64, uv models.ProductOptionValueUpdateInput) (*models.ProductOptionValue, error) {
+	return dc.updateProductOptionValue(valueID, uv)
+}
+
+// updateProductOptionValue sends a product option value update, either a ProductOptionValueUpdateInput or a
+// ProductOptionValuePatch
+func (dc *V1Client) updateProductOptionValue(valueID uint64, body interface{}) (*models.ProductOptionValue, error) {
 	valueIDString := convertIDToString(valueID)
 	u := dc.buildURL(nil, "product_option_values", valueIDString)
-	v := models.ProductOptionValue{}
-
-	err := dc.patch(u, uv, &v)
-	if err != nil {
-		return nil, err
-	}
-
-	return &v, nil
+	return patch[models.ProductOptionValue](dc, u, body)
 }
 
 func (dc *V1Client) DeleteProductOptionValue(optionID uint64) error {
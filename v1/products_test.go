@@ -1,15 +1,20 @@
 package dairyclient_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
+	"github.com/dairycart/dairyclient/v1"
 	"github.com/dairycart/dairymodels/v1"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func buildNotFoundProductResponse(sku string) string {
@@ -891,6 +896,42 @@ func TestGetProductRoots(t *testing.T) {
 	})
 }
 
+func TestGetAllProductRoots(t *testing.T) {
+	// the server's default limit makes these pages shorter than any client-side page size
+	pages := map[string]string{
+		"1": `{"count": 3, "limit": 2, "page": 1, "product_roots": [{"id": 1}, {"id": 2}]}`,
+		"2": `{"count": 3, "limit": 2, "page": 2, "product_roots": [{"id": 3}]}`,
+	}
+
+	t.Run("normal usage", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/product_roots": func(res http.ResponseWriter, req *http.Request) {
+				fmt.Fprint(res, pages[req.URL.Query().Get("page")])
+			},
+		}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		actual, err := c.GetAllProductRoots()
+		assert.Nil(t, err)
+		require.Len(t, actual, 3)
+		assert.Equal(t, uint64(3), actual[2].ID)
+	})
+
+	t.Run("with error response", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/product_roots": generateGetHandler(t, exampleBadJSON, http.StatusOK),
+		}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		_, err := c.GetAllProductRoots()
+		assert.NotNil(t, err)
+	})
+}
+
 func TestDeleteProductRoot(t *testing.T) {
 	existentID := uint64(1)
 	nonexistentID := uint64(2)
@@ -1265,3 +1306,404 @@ func TestDeleteProductOptionValue(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 }
+
+func TestCreateProductRoot(t *testing.T) {
+	exampleResponseJSON := loadExampleResponse(t, "created_product_root")
+	expectedBody := `
+		{
+			"name": "Your Favorite Band's T-Shirt",
+			"sku_prefix": "t-shirt",
+			"taxable": true,
+			"cost": 20
+		}
+	`
+	exampleInput := dairyclient.ProductRootCreationInput{
+		Name:      "Your Favorite Band's T-Shirt",
+		SKUPrefix: "t-shirt",
+		Taxable:   true,
+		Cost:      20,
+	}
+
+	t.Run("normal operation", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/product_root": generatePostHandler(t, expectedBody, exampleResponseJSON, http.StatusCreated),
+		}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		expected := &models.ProductRoot{
+			ID:                 1,
+			Name:               "Your Favorite Band's T-Shirt",
+			Subtitle:           "A t-shirt you can wear",
+			Description:        "Wear this if you'd like. Or don't, I'm not in charge of your actions",
+			SKUPrefix:          "t-shirt",
+			Manufacturer:       "Record Company",
+			Brand:              "Your Favorite Band",
+			Taxable:            true,
+			Cost:               20,
+			ProductWeight:      1,
+			ProductHeight:      5,
+			ProductWidth:       5,
+			ProductLength:      5,
+			PackageWeight:      1,
+			PackageHeight:      5,
+			PackageWidth:       5,
+			PackageLength:      5,
+			QuantityPerPackage: 1,
+			AvailableOn:        buildTestTime(t),
+			CreatedOn:          buildTestTime(t),
+			Options:            []models.ProductOption{},
+			Products:           []models.Product{},
+		}
+
+		actual, err := c.CreateProductRoot(exampleInput)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("with bad server response", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/product_root": generatePostHandler(t, expectedBody, exampleBadJSON, http.StatusCreated),
+		}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		_, err := c.CreateProductRoot(exampleInput)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestUpdateProductRoot(t *testing.T) {
+	existentID, nonexistentID := uint64(1), uint64(2)
+	exampleResponseJSON := loadExampleResponse(t, "updated_product_root")
+	expectedBody := `
+		{
+			"sku_prefix": "band-shirt"
+		}
+	`
+	exampleInput := dairyclient.ProductRootUpdateInput{SKUPrefix: "band-shirt"}
+
+	handlers := map[string]http.HandlerFunc{
+		fmt.Sprintf("/v1/product_root/%d", existentID):    generatePatchHandler(t, expectedBody, exampleResponseJSON, http.StatusOK),
+		fmt.Sprintf("/v1/product_root/%d", nonexistentID): generatePatchHandler(t, expectedBody, buildNotFoundProductRootResponse(nonexistentID), http.StatusNotFound),
+	}
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("normal operation", func(*testing.T) {
+		actual, err := c.UpdateProductRoot(existentID, exampleInput)
+		assert.Nil(t, err)
+		assert.Equal(t, "band-shirt", actual.SKUPrefix)
+		assert.Equal(t, buildTestDairytime(t), actual.UpdatedOn)
+	})
+
+	t.Run("for nonexistent product root", func(*testing.T) {
+		_, err := c.UpdateProductRoot(nonexistentID, exampleInput)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("turning taxable off", func(*testing.T) {
+		taxable := false
+		b, err := json.Marshal(dairyclient.ProductRootUpdateInput{Taxable: &taxable})
+		require.Nil(t, err)
+		assert.JSONEq(t, `{"taxable": false}`, string(b))
+	})
+}
+
+func TestGenerateVariants(t *testing.T) {
+	root := dairyclient.ProductRootCreationInput{
+		Name:      "Your Favorite Band's T-Shirt",
+		SKUPrefix: "t-shirt",
+		Brand:     "Your Favorite Band",
+		Cost:      10,
+	}
+	options := []models.ProductOption{
+		{Name: "size", Values: []models.ProductOptionValue{{Value: "Small"}, {Value: "Extra Large"}}},
+		{Name: "color", Values: []models.ProductOptionValue{{Value: "red"}, {Value: "blue"}}},
+	}
+	base := models.ProductCreationInput{Price: 20, Brand: "Bootleg"}
+
+	t.Run("normal usage", func(*testing.T) {
+		actual := dairyclient.GenerateVariants(root, options, base)
+
+		var skus []string
+		for _, v := range actual {
+			skus = append(skus, v.SKU)
+			assert.Equal(t, root.Name, v.Name, "variants should inherit empty fields from the root")
+			assert.Equal(t, "Bootleg", v.Brand, "variants should keep fields set on the base")
+			assert.Equal(t, float32(10), v.Cost)
+			assert.Equal(t, float32(20), v.Price)
+		}
+		expected := []string{
+			"t-shirt-small-red",
+			"t-shirt-small-blue",
+			"t-shirt-extra-large-red",
+			"t-shirt-extra-large-blue",
+		}
+		assert.Equal(t, expected, skus)
+	})
+
+	t.Run("without options", func(*testing.T) {
+		actual := dairyclient.GenerateVariants(root, nil, base)
+		require.Len(t, actual, 1)
+		assert.Equal(t, "t-shirt", actual[0].SKU)
+	})
+
+	t.Run("without SKU prefix", func(*testing.T) {
+		actual := dairyclient.GenerateVariants(dairyclient.ProductRootCreationInput{}, options[:1], base)
+		require.Len(t, actual, 2)
+		assert.Equal(t, "small", actual[0].SKU)
+		assert.Equal(t, "extra-large", actual[1].SKU)
+	})
+
+	t.Run("with taxable root", func(*testing.T) {
+		taxableRoot := root
+		taxableRoot.Taxable = true
+		for _, v := range dairyclient.GenerateVariants(taxableRoot, options, base) {
+			assert.True(t, v.Taxable, "variants should inherit taxable from the root")
+		}
+	})
+}
+
+func TestCreateProductRootWithVariants(t *testing.T) {
+	root := dairyclient.ProductRootCreationInput{SKUPrefix: "t-shirt"}
+	options := []models.ProductOptionCreationInput{{Name: "example_option", Values: []string{"one", "two", "three"}}}
+
+	t.Run("normal operation", func(*testing.T) {
+		var createdSKUs []string
+		handlers := map[string]http.HandlerFunc{
+			"/v1/product_root": generatePostHandler(t, `{"sku_prefix": "t-shirt"}`, loadExampleResponse(t, "created_product_root"), http.StatusCreated),
+			"/v1/product/1/options": func(res http.ResponseWriter, req *http.Request) {
+				fmt.Fprint(res, loadExampleResponse(t, "created_product_option"))
+			},
+			"/v1/product_root/1/product": func(res http.ResponseWriter, req *http.Request) {
+				np := models.ProductCreationInput{}
+				require.Nil(t, json.NewDecoder(req.Body).Decode(&np))
+				createdSKUs = append(createdSKUs, np.SKU)
+				fmt.Fprint(res, loadExampleResponse(t, "created_product"))
+			},
+			"/v1/product_root/1": generateGetHandler(t, loadExampleResponse(t, "product_root"), http.StatusOK),
+		}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		actual, err := c.CreateProductRootWithVariants(root, options, models.ProductCreationInput{})
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1), actual.ID)
+		assert.Equal(t, []string{"t-shirt-one", "t-shirt-two", "t-shirt-three"}, createdSKUs)
+	})
+
+	t.Run("with failure creating option", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/product_root":      generatePostHandler(t, `{"sku_prefix": "t-shirt"}`, loadExampleResponse(t, "created_product_root"), http.StatusCreated),
+			"/v1/product/1/options": generatePostHandler(t, `{"name":"example_option","values":["one","two","three"]}`, buildNotFoundProductOptionResponse(1), http.StatusNotFound),
+		}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		_, err := c.CreateProductRootWithVariants(root, options, models.ProductCreationInput{})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("with failure creating root", func(*testing.T) {
+		ts := httptest.NewTLSServer(http.NotFoundHandler())
+		c := buildTestClient(t, ts)
+		ts.Close()
+
+		_, err := c.CreateProductRootWithVariants(root, options, models.ProductCreationInput{})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestGetAllProducts(t *testing.T) {
+	pages := map[string]string{
+		"1": `{"count": 3, "limit": 2, "page": 1, "products": [{"id": 1}, {"id": 2}]}`,
+		"2": `{"count": 3, "limit": 2, "page": 2, "products": [{"id": 3}]}`,
+	}
+
+	t.Run("normal usage", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/products": func(res http.ResponseWriter, req *http.Request) {
+				fmt.Fprint(res, pages[req.URL.Query().Get("page")])
+			},
+		}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		actual, err := c.GetAllProducts()
+		assert.Nil(t, err)
+		require.Len(t, actual, 3)
+		assert.Equal(t, uint64(3), actual[2].ID)
+	})
+
+	t.Run("with error response", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/products": generateGetHandler(t, exampleBadJSON, http.StatusOK),
+		}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		_, err := c.GetAllProducts()
+		assert.NotNil(t, err)
+	})
+}
+
+func TestGetArchivedProducts(t *testing.T) {
+	exampleResponseJSON := loadExampleResponse(t, "archived_products")
+
+	t.Run("normal usage", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/products": func(res http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, "true", req.URL.Query().Get(dairyclient.IncludeArchivedKey))
+				fmt.Fprint(res, exampleResponseJSON)
+			},
+		}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		actual, err := c.GetArchivedProducts(nil)
+		assert.Nil(t, err)
+		require.Len(t, actual, 1)
+		assert.Equal(t, "t-shirt-medium-red", actual[0].SKU)
+	})
+
+	t.Run("with bad server response", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/products": generateGetHandler(t, exampleBadJSON, http.StatusOK),
+		}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		_, err := c.GetArchivedProducts(nil)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestRestoreProduct(t *testing.T) {
+	existentSKU := "existent_sku"
+	nonexistentSKU := "nonexistent_sku"
+	exampleResponseJSON := loadExampleResponse(t, "restored_product")
+
+	handlers := map[string]http.HandlerFunc{
+		fmt.Sprintf("/v1/product/%s/restore", existentSKU):    generatePostHandler(t, "{}", exampleResponseJSON, http.StatusOK),
+		fmt.Sprintf("/v1/product/%s/restore", nonexistentSKU): generatePostHandler(t, "{}", buildNotFoundProductResponse(nonexistentSKU), http.StatusNotFound),
+	}
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("with archived product", func(*testing.T) {
+		actual, err := c.RestoreProduct(existentSKU)
+		assert.Nil(t, err)
+		assert.Nil(t, actual.ArchivedOn)
+		assert.Equal(t, buildTestDairytime(t), actual.UpdatedOn)
+	})
+
+	t.Run("with nonexistent product", func(*testing.T) {
+		_, err := c.RestoreProduct(nonexistentSKU)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestRestoreProductRoot(t *testing.T) {
+	existentID, nonexistentID := uint64(1), uint64(2)
+	exampleResponseJSON := loadExampleResponse(t, "created_product_root")
+
+	handlers := map[string]http.HandlerFunc{
+		fmt.Sprintf("/v1/product_root/%d/restore", existentID):    generatePostHandler(t, "{}", exampleResponseJSON, http.StatusOK),
+		fmt.Sprintf("/v1/product_root/%d/restore", nonexistentID): generatePostHandler(t, "{}", buildNotFoundProductRootResponse(nonexistentID), http.StatusNotFound),
+	}
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("with archived product root", func(*testing.T) {
+		actual, err := c.RestoreProductRoot(existentID)
+		assert.Nil(t, err)
+		assert.Nil(t, actual.ArchivedOn)
+	})
+
+	t.Run("with nonexistent product root", func(*testing.T) {
+		_, err := c.RestoreProductRoot(nonexistentID)
+		assert.NotNil(t, err)
+	})
+}
+
+func FuzzProductPaths(f *testing.F) {
+	for _, sku := range []string{
+		"sku",
+		"t-shirt-small-red",
+		"a/b",
+		"../products",
+		"..",
+		".",
+		"a?b=c",
+		"a#b",
+		"with spaces",
+		"100%",
+		"%2F",
+		"semi;colon",
+		"ünïcödé",
+		"",
+	} {
+		f.Add(sku)
+	}
+
+	var method, path string
+	exampleResponse := loadExampleResponse(f, "product")
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		method, path = req.Method, req.URL.EscapedPath()
+		if req.URL.RawQuery != "" {
+			path += "?" + req.URL.RawQuery
+		}
+		fmt.Fprint(res, exampleResponse)
+	}))
+	defer ts.Close()
+
+	f.Fuzz(func(t *testing.T, sku string) {
+		c := buildTestClient(t, ts)
+		calls := map[string]func() error{
+			http.MethodHead: func() error {
+				_, err := c.ProductExists(sku)
+				return err
+			},
+			http.MethodGet: func() error {
+				_, err := c.GetProduct(sku)
+				return err
+			},
+			http.MethodPatch: func() error {
+				_, err := c.UpdateProduct(sku, models.ProductUpdateInput{Name: "name"})
+				return err
+			},
+		}
+
+		for expectedMethod, call := range calls {
+			method, path = "", ""
+			err := call()
+
+			if sku == "" {
+				assert.IsType(t, &dairyclient.EmptyIdentifierError{}, err)
+				assert.Empty(t, method, "no request should be made for an empty SKU")
+				continue
+			}
+			require.Nil(t, err)
+			require.Equal(t, expectedMethod, method)
+
+			segments := strings.Split(path, "/")
+			require.Len(t, segments, 4, "%q should produce exactly one path segment, got %q", sku, path)
+			assert.Equal(t, []string{"", "v1", "product"}, segments[:3])
+			actual, err := url.PathUnescape(segments[3])
+			require.Nil(t, err)
+			assert.Equal(t, sku, actual)
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package dairyclient
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dairycart/dairymodels/v1"
+)
+
+// DefaultQueryPageSize is how many products a ProductIterator requests per page when the query doesn't say
+const DefaultQueryPageSize = 50
+
+// ProductQuery describes the products to find with QueryProducts. Zero values, and nil pointers, are ignored.
+//
+// The server's product list only understands the paging, archival and time window filters, so those are sent
+// as query parameters. The remaining filters are applied client-side to each page as it arrives.
+type ProductQuery struct {
+	// sent to the server
+	CreatedAfter    time.Time
+	CreatedBefore   time.Time
+	UpdatedAfter    time.Time
+	UpdatedBefore   time.Time
+	IncludeArchived bool
+	PageSize        uint64
+
+	// applied client-side
+	Brand        string
+	Manufacturer string
+	NameContains string
+	MinPrice     float32
+	MaxPrice     float32
+	OnSale       *bool
+	Taxable      *bool
+	MinQuantity  *uint32
+	MaxQuantity  *uint32
+}
+
+func (pq ProductQuery) pageSize() uint64 {
+	if pq.PageSize == 0 {
+		return DefaultQueryPageSize
+	}
+	return pq.PageSize
+}
+
+func (pq ProductQuery) queryFilter(page uint64) map[string]string {
+	out := map[string]string{
+		"page":  convertIDToString(page),
+		"limit": convertIDToString(pq.pageSize()),
+	}
+	times := map[string]time.Time{
+		"created_after":  pq.CreatedAfter,
+		"created_before": pq.CreatedBefore,
+		"updated_after":  pq.UpdatedAfter,
+		"updated_before": pq.UpdatedBefore,
+	}
+	for k, t := range times {
+		if !t.IsZero() {
+			out[k] = strconv.FormatInt(t.Unix(), 10)
+		}
+	}
+	if pq.IncludeArchived {
+		out[IncludeArchivedKey] = "true"
+	}
+	return out
+}
+
+// Matches reports whether a product passes the query's client-side filters. Brand, manufacturer and name
+// comparisons ignore case.
+func (pq ProductQuery) Matches(p models.Product) bool {
+	if pq.Brand != "" && !strings.EqualFold(pq.Brand, p.Brand) {
+		return false
+	}
+	if pq.Manufacturer != "" && !strings.EqualFold(pq.Manufacturer, p.Manufacturer) {
+		return false
+	}
+	if pq.NameContains != "" && !strings.Contains(strings.ToLower(p.Name), strings.ToLower(pq.NameContains)) {
+		return false
+	}
+	if pq.MinPrice != 0 && p.Price < pq.MinPrice {
+		return false
+	}
+	if pq.MaxPrice != 0 && p.Price > pq.MaxPrice {
+		return false
+	}
+	if pq.OnSale != nil && p.OnSale != *pq.OnSale {
+		return false
+	}
+	if pq.Taxable != nil && p.Taxable != *pq.Taxable {
+		return false
+	}
+	if pq.MinQuantity != nil && p.Quantity < *pq.MinQuantity {
+		return false
+	}
+	if pq.MaxQuantity != nil && p.Quantity > *pq.MaxQuantity {
+		return false
+	}
+	return true
+}
+
+// ProductIterator walks the products matching a ProductQuery, fetching pages only as they're needed. Use it
+// like a bufio.Scanner:
+//
+//	it := c.QueryProducts(q)
+//	for it.Next() {
+//		p := it.Product()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type ProductIterator struct {
+	dc      *V1Client
+	query   ProductQuery
+	page    uint64
+	fetched uint64
+	buf     []models.Product
+	current models.Product
+	last    bool
+	err     error
+}
+
+// QueryProducts returns an iterator over the products matching q. No request is made until Next is called.
+func (dc *V1Client) QueryProducts(q ProductQuery) *ProductIterator {
+	return &ProductIterator{dc: dc, query: q}
+}
+
+// Next advances to the next matching product, fetching another page if necessary. It returns false once the
+// products run out or a request fails.
+func (it *ProductIterator) Next() bool {
+	for it.err == nil {
+		for len(it.buf) > 0 {
+			p := it.buf[0]
+			it.buf = it.buf[1:]
+			if it.query.Matches(p) {
+				it.current = p
+				return true
+			}
+		}
+		if it.last {
+			return false
+		}
+
+		it.page++
+		u := it.dc.buildURL(it.query.queryFilter(it.page), "products")
+		pl, err := get[models.ProductListResponse](it.dc, u)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		// the server may cap pages below the requested size, so the listing's count decides when it's done
+		it.buf = pl.Products
+		it.fetched += uint64(len(pl.Products))
+		it.last = len(pl.Products) == 0 || it.fetched >= uint64(pl.Count)
+	}
+	return false
+}
+
+// Product returns the product Next advanced to
+func (it *ProductIterator) Product() models.Product {
+	return it.current
+}
+
+// Err returns the error that stopped iteration, if any
+func (it *ProductIterator) Err() error {
+	return it.err
+}
+
+// All drains the iterator, returning every remaining matching product
+func (it *ProductIterator) All() ([]models.Product, error) {
+	var out []models.Product
+	for it.Next() {
+		out = append(out, it.Product())
+	}
+	return out, it.Err()
+}
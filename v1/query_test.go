@@ -0,0 +1,137 @@
+package dairyclient_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dairycart/dairyclient/v1"
+	"github.com/dairycart/dairymodels/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductQueryMatches(t *testing.T) {
+	onSale, zero := true, uint32(0)
+	p := models.Product{Name: "Band T-Shirt", Brand: "Band", Manufacturer: "Shirts Inc", Price: 20, Quantity: 5, OnSale: true}
+
+	testCases := []struct {
+		name     string
+		query    dairyclient.ProductQuery
+		expected bool
+	}{
+		{name: "empty query", query: dairyclient.ProductQuery{}, expected: true},
+		{name: "brand ignores case", query: dairyclient.ProductQuery{Brand: "band"}, expected: true},
+		{name: "other brand", query: dairyclient.ProductQuery{Brand: "Other"}, expected: false},
+		{name: "manufacturer", query: dairyclient.ProductQuery{Manufacturer: "Shirts Inc"}, expected: true},
+		{name: "name substring", query: dairyclient.ProductQuery{NameContains: "t-shirt"}, expected: true},
+		{name: "price in range", query: dairyclient.ProductQuery{MinPrice: 10, MaxPrice: 20}, expected: true},
+		{name: "price below range", query: dairyclient.ProductQuery{MinPrice: 25}, expected: false},
+		{name: "on sale", query: dairyclient.ProductQuery{OnSale: &onSale}, expected: true},
+		{name: "out of stock", query: dairyclient.ProductQuery{MaxQuantity: &zero}, expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.query.Matches(p))
+		})
+	}
+}
+
+func TestQueryProducts(t *testing.T) {
+	var catalog []models.Product
+	for i := 1; i <= 5; i++ {
+		catalog = append(catalog, models.Product{ID: uint64(i), SKU: "sku-" + strconv.Itoa(i), Price: float32(i * 10)})
+	}
+
+	var requestedPages []string
+	handlers := map[string]http.HandlerFunc{
+		"/v1/products": func(res http.ResponseWriter, req *http.Request) {
+			query := req.URL.Query()
+			requestedPages = append(requestedPages, query.Get("page"))
+			assert.Equal(t, "2", query.Get("limit"))
+			assert.Equal(t, "true", query.Get(dairyclient.IncludeArchivedKey))
+			assert.Equal(t, "1512921523", query.Get("created_after"))
+			assert.Empty(t, query.Get("max_price"), "client-side filters should not be sent")
+
+			page, _ := strconv.Atoi(query.Get("page"))
+			start, end := (page-1)*2, page*2
+			if start > len(catalog) {
+				start = len(catalog)
+			}
+			if end > len(catalog) {
+				end = len(catalog)
+			}
+			json.NewEncoder(res).Encode(models.ProductListResponse{ListResponse: models.ListResponse{Count: uint64(len(catalog))}, Products: catalog[start:end]})
+		},
+	}
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	q := dairyclient.ProductQuery{
+		CreatedAfter:    time.Unix(1512921523, 0),
+		IncludeArchived: true,
+		PageSize:        2,
+		MinPrice:        20,
+		MaxPrice:        40,
+	}
+
+	t.Run("normal usage", func(*testing.T) {
+		requestedPages = nil
+		products, err := c.QueryProducts(q).All()
+		require.Nil(t, err)
+
+		var skus []string
+		for _, p := range products {
+			skus = append(skus, p.SKU)
+		}
+		assert.Equal(t, []string{"sku-2", "sku-3", "sku-4"}, skus)
+		assert.Equal(t, []string{"1", "2", "3"}, requestedPages)
+	})
+
+	t.Run("is lazy", func(*testing.T) {
+		requestedPages = nil
+		it := c.QueryProducts(q)
+		assert.Empty(t, requestedPages, "no request should be made before Next")
+
+		require.True(t, it.Next())
+		assert.Equal(t, "sku-2", it.Product().SKU)
+		assert.Equal(t, []string{"1"}, requestedPages)
+	})
+
+	t.Run("with pages shorter than the page size", func(*testing.T) {
+		ts := httptest.NewTLSServer(handlerGenerator(map[string]http.HandlerFunc{
+			"/v1/products": func(res http.ResponseWriter, req *http.Request) {
+				page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+				var products []models.Product
+				if page <= 3 {
+					products = catalog[page-1 : page]
+				}
+				json.NewEncoder(res).Encode(models.ProductListResponse{ListResponse: models.ListResponse{Count: 3}, Products: products})
+			},
+		}))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		products, err := c.QueryProducts(dairyclient.ProductQuery{PageSize: 2}).All()
+		require.Nil(t, err)
+		assert.Len(t, products, 3)
+	})
+
+	t.Run("with error", func(*testing.T) {
+		ts := httptest.NewTLSServer(handlerGenerator(map[string]http.HandlerFunc{
+			"/v1/products": generateGetHandler(t, exampleBadJSON, http.StatusOK),
+		}))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		it := c.QueryProducts(dairyclient.ProductQuery{})
+		assert.False(t, it.Next())
+		assert.NotNil(t, it.Err())
+	})
+}
@@ -0,0 +1,194 @@
+package dairyclient
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/dairycart/dairymodels/v1"
+
+	"github.com/pkg/errors"
+)
+
+// ErrUnknownStore is returned when a registry is asked for a store it was not configured with
+var ErrUnknownStore = errors.New("no store configured with that name")
+
+// StoreConfig describes how to reach and log in to a single store
+type StoreConfig struct {
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// RegistryConfig is the format of a store registry config file
+type RegistryConfig struct {
+	Stores []StoreConfig `json:"stores"`
+}
+
+type storeEntry struct {
+	mu     sync.Mutex
+	config StoreConfig
+	client *V1Client
+}
+
+// StoreRegistry holds one V1Client per named store. Each store logs in the first time it is used, and keeps
+// its own session, so one store being down or logged out never affects the others.
+type StoreRegistry struct {
+	// Transport, if set, is used by every store's http.Client
+	Transport http.RoundTripper
+
+	stores map[string]*storeEntry
+}
+
+// NewStoreRegistry builds a registry from a list of store configs. No store is contacted until it is used.
+func NewStoreRegistry(configs []StoreConfig) (*StoreRegistry, error) {
+	sr := &StoreRegistry{stores: map[string]*storeEntry{}}
+	for _, c := range configs {
+		if c.Name == "" {
+			return nil, errors.New("store config is missing a name")
+		}
+		if _, ok := sr.stores[c.Name]; ok {
+			return nil, errors.Errorf("store %q is configured more than once", c.Name)
+		}
+		sr.stores[c.Name] = &storeEntry{config: c}
+	}
+	return sr, nil
+}
+
+// LoadStoreRegistry builds a registry from a JSON config file
+func LoadStoreRegistry(path string) (*StoreRegistry, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "encountered error reading store registry config")
+	}
+
+	var rc RegistryConfig
+	if err := json.Unmarshal(data, &rc); err != nil {
+		return nil, errors.Wrap(err, "encountered error parsing store registry config")
+	}
+	return NewStoreRegistry(rc.Stores)
+}
+
+// Names returns the name of every configured store, sorted
+func (sr *StoreRegistry) Names() []string {
+	names := make([]string, 0, len(sr.stores))
+	for name := range sr.stores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Store returns the client for a named store, logging in to it if it has no session yet
+func (sr *StoreRegistry) Store(name string) (*V1Client, error) {
+	e, ok := sr.stores[name]
+	if !ok {
+		return nil, ErrUnknownStore
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.client == nil {
+		c, err := sr.login(e.config)
+		if err != nil {
+			return nil, err
+		}
+		e.client = c
+	}
+	return e.client, nil
+}
+
+// Refresh discards a store's session and logs in to it again
+func (sr *StoreRegistry) Refresh(name string) error {
+	e, ok := sr.stores[name]
+	if !ok {
+		return ErrUnknownStore
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	c, err := sr.login(e.config)
+	if err != nil {
+		return err
+	}
+	e.client = c
+	return nil
+}
+
+func (sr *StoreRegistry) login(sc StoreConfig) (*V1Client, error) {
+	c, err := NewV1Client(sc.URL, sc.Username, sc.Password, &http.Client{Transport: sr.Transport})
+	if err != nil {
+		return nil, errors.Wrapf(err, "encountered error logging in to store %q", sc.Name)
+	}
+	return c, nil
+}
+
+func isUnauthorized(err error) bool {
+	ce, ok := err.(*ClientError)
+	return ok && ce.FromAPI != nil && ce.FromAPI.Status == http.StatusUnauthorized
+}
+
+// StoreResult is one store's outcome from a fan-out call
+type StoreResult[T any] struct {
+	Value T
+	Err   error
+}
+
+// FanOut calls fn against every store concurrently and collects the results by store name. A store that
+// rejects its session is logged in to again and fn retried once.
+func FanOut[T any](sr *StoreRegistry, fn func(*V1Client) (T, error)) map[string]StoreResult[T] {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = map[string]StoreResult[T]{}
+	)
+
+	for name := range sr.stores {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			v, err := CallStore(sr, name, fn)
+
+			mu.Lock()
+			results[name] = StoreResult[T]{Value: v, Err: err}
+			mu.Unlock()
+		}(name)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// CallStore runs fn against a single named store, logging in to it again and retrying once if its session
+// has expired
+func CallStore[T any](sr *StoreRegistry, name string, fn func(*V1Client) (T, error)) (T, error) {
+	var zero T
+
+	c, err := sr.Store(name)
+	if err != nil {
+		return zero, err
+	}
+
+	v, err := fn(c)
+	if !isUnauthorized(err) {
+		return v, err
+	}
+
+	if err := sr.Refresh(name); err != nil {
+		return zero, err
+	}
+	if c, err = sr.Store(name); err != nil {
+		return zero, err
+	}
+	return fn(c)
+}
+
+// GetProductFromAllStores fetches a SKU from every store
+func (sr *StoreRegistry) GetProductFromAllStores(sku string) map[string]StoreResult[*models.Product] {
+	return FanOut(sr, func(c *V1Client) (*models.Product, error) {
+		return c.GetProduct(sku)
+	})
+}
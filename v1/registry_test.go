@@ -0,0 +1,159 @@
+package dairyclient_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dairycart/dairyclient/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildRegistryTestServer(t *testing.T, logins *int32, productHandler http.HandlerFunc) *httptest.Server {
+	handlers := map[string]http.HandlerFunc{
+		"/login": func(res http.ResponseWriter, req *http.Request) {
+			atomic.AddInt32(logins, 1)
+			http.SetCookie(res, buildTestCookie())
+		},
+		"/v1/product/sku": productHandler,
+	}
+	return httptest.NewTLSServer(handlerGenerator(handlers))
+}
+
+func TestLoadStoreRegistry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registry")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	t.Run("normal usage", func(*testing.T) {
+		path := filepath.Join(dir, "stores.json")
+		config := `
+			{
+				"stores": [
+					{"name": "us", "url": "https://us.example.com", "username": "user", "password": "pass"},
+					{"name": "eu", "url": "https://eu.example.com", "username": "user", "password": "pass"}
+				]
+			}
+		`
+		require.Nil(t, ioutil.WriteFile(path, []byte(config), 0600))
+
+		sr, err := dairyclient.LoadStoreRegistry(path)
+		require.Nil(t, err)
+		assert.Equal(t, []string{"eu", "us"}, sr.Names())
+	})
+
+	t.Run("with duplicate store", func(*testing.T) {
+		path := filepath.Join(dir, "duplicate.json")
+		config := `{"stores": [{"name": "us"}, {"name": "us"}]}`
+		require.Nil(t, ioutil.WriteFile(path, []byte(config), 0600))
+
+		_, err := dairyclient.LoadStoreRegistry(path)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("with invalid file", func(*testing.T) {
+		path := filepath.Join(dir, "invalid.json")
+		require.Nil(t, ioutil.WriteFile(path, []byte(exampleBadJSON), 0600))
+
+		_, err := dairyclient.LoadStoreRegistry(path)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("with nonexistent file", func(*testing.T) {
+		_, err := dairyclient.LoadStoreRegistry(filepath.Join(dir, "nope.json"))
+		assert.NotNil(t, err)
+	})
+}
+
+func TestStoreRegistry(t *testing.T) {
+	exampleProduct := loadExampleResponse(t, "product")
+
+	t.Run("unknown store", func(*testing.T) {
+		sr, err := dairyclient.NewStoreRegistry(nil)
+		require.Nil(t, err)
+
+		_, err = sr.Store("nope")
+		assert.Equal(t, dairyclient.ErrUnknownStore, err)
+		assert.Equal(t, dairyclient.ErrUnknownStore, sr.Refresh("nope"))
+	})
+
+	t.Run("logs in lazily and once", func(*testing.T) {
+		var logins int32
+		ts := buildRegistryTestServer(t, &logins, generateGetHandler(t, exampleProduct, http.StatusOK))
+		defer ts.Close()
+
+		sr, err := dairyclient.NewStoreRegistry([]dairyclient.StoreConfig{{Name: "us", URL: ts.URL}})
+		require.Nil(t, err)
+		sr.Transport = ts.Client().Transport
+		assert.Equal(t, int32(0), atomic.LoadInt32(&logins))
+
+		first, err := sr.Store("us")
+		require.Nil(t, err)
+		second, err := sr.Store("us")
+		require.Nil(t, err)
+		assert.True(t, first == second, "the same client should be reused")
+		assert.Equal(t, int32(1), atomic.LoadInt32(&logins))
+	})
+
+	t.Run("fan out", func(*testing.T) {
+		var usLogins, euLogins int32
+		us := buildRegistryTestServer(t, &usLogins, generateGetHandler(t, exampleProduct, http.StatusOK))
+		defer us.Close()
+		eu := buildRegistryTestServer(t, &euLogins, generateGetHandler(t, buildNotFoundProductResponse("sku"), http.StatusNotFound))
+		defer eu.Close()
+
+		sr, err := dairyclient.NewStoreRegistry([]dairyclient.StoreConfig{
+			{Name: "us", URL: us.URL},
+			{Name: "eu", URL: eu.URL},
+			{Name: "down", URL: "https://127.0.0.1:1"},
+		})
+		require.Nil(t, err)
+		sr.Transport = us.Client().Transport
+
+		results := sr.GetProductFromAllStores("sku")
+		require.Len(t, results, 3)
+
+		assert.Nil(t, results["us"].Err)
+		require.NotNil(t, results["us"].Value)
+		assert.Equal(t, "sku", results["us"].Value.SKU)
+
+		assert.NotNil(t, results["eu"].Err)
+		assert.Nil(t, results["eu"].Value)
+
+		assert.NotNil(t, results["down"].Err, "a store that can't be reached should only fail its own result")
+	})
+
+	t.Run("refreshes expired sessions", func(*testing.T) {
+		var logins, calls int32
+		ts := buildRegistryTestServer(t, &logins, func(res http.ResponseWriter, req *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				res.WriteHeader(http.StatusUnauthorized)
+				res.Write([]byte(`{"status":401,"message":"session expired"}`))
+				return
+			}
+			res.Write([]byte(exampleProduct))
+		})
+		defer ts.Close()
+
+		sr, err := dairyclient.NewStoreRegistry([]dairyclient.StoreConfig{{Name: "us", URL: ts.URL}})
+		require.Nil(t, err)
+		sr.Transport = ts.Client().Transport
+
+		p, err := dairyclient.CallStore(sr, "us", func(c *dairyclient.V1Client) (string, error) {
+			p, err := c.GetProduct("sku")
+			if err != nil {
+				return "", err
+			}
+			return p.SKU, nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "sku", p)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&logins), "the store should have logged in again")
+	})
+}
@@ -0,0 +1,69 @@
+package replicate
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/dairycart/dairyclient/v1/internal/atomicfile"
+)
+
+// IDMap maps a source store's IDs to the IDs the target store assigned to the same entities
+type IDMap map[uint64]uint64
+
+// Mapping records every entity a Replicator has created on the target so far, keyed by source ID
+type Mapping struct {
+	ProductRoots        IDMap `json:"product_roots"`
+	ProductOptions      IDMap `json:"product_options"`
+	ProductOptionValues IDMap `json:"product_option_values"`
+	Products            IDMap `json:"products"`
+	Discounts           IDMap `json:"discounts"`
+}
+
+// NewMapping returns an empty Mapping
+func NewMapping() *Mapping {
+	return &Mapping{
+		ProductRoots:        IDMap{},
+		ProductOptions:      IDMap{},
+		ProductOptionValues: IDMap{},
+		Products:            IDMap{},
+		Discounts:           IDMap{},
+	}
+}
+
+// LoadMapping reads a Mapping from a JSON file. A missing file yields an empty Mapping, which replicates
+// everything.
+func LoadMapping(path string) (*Mapping, error) {
+	m := NewMapping()
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return m, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	m.fill()
+	return m, nil
+}
+
+// fill replaces any map left nil by a partial mapping file with an empty one
+func (m *Mapping) fill() {
+	for _, im := range []*IDMap{&m.ProductRoots, &m.ProductOptions, &m.ProductOptionValues, &m.Products, &m.Discounts} {
+		if *im == nil {
+			*im = IDMap{}
+		}
+	}
+}
+
+// Save writes the mapping to a file using internal/atomicfile
+func (m *Mapping) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return atomicfile.WriteFile(path, data)
+}
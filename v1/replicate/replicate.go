@@ -0,0 +1,269 @@
+// Package replicate copies a Dairycart catalog from one store to another. Product roots, their options and
+// option values, their products, and discounts are read from a source client and recreated on a target
+// client, with the numeric IDs that link them remapped to the IDs the target assigns. Progress is recorded in
+// a Mapping, so an interrupted run can be resumed without creating anything twice, and Verify compares the two
+// catalogs once a run is complete.
+package replicate
+
+import (
+	"github.com/dairycart/dairyclient/v1"
+	"github.com/dairycart/dairymodels/v1"
+
+	"github.com/pkg/errors"
+)
+
+// Source is the subset of the client a Replicator reads from
+type Source interface {
+	GetAllProductRoots() ([]models.ProductRoot, error)
+	GetProductRoot(rootID uint64) (*models.ProductRoot, error)
+	GetAllDiscounts() ([]models.Discount, error)
+	GetDiscountByID(discountID uint64) (*models.Discount, error)
+}
+
+// Target is the subset of the client a Replicator writes to
+type Target interface {
+	Source
+	CreateProductRoot(nr dairyclient.ProductRootCreationInput) (*models.ProductRoot, error)
+	CreateProductOption(productRootID uint64, no models.ProductOptionCreationInput) (*models.ProductOption, error)
+	CreateProductOptionValue(optionID uint64, nv models.ProductOptionValueCreationInput) (*models.ProductOptionValue, error)
+	CreateProductRootVariant(rootID uint64, np models.ProductCreationInput) (*models.Product, error)
+	CreateDiscount(nd models.DiscountCreationInput) (*models.Discount, error)
+}
+
+// Replicator copies the catalog of a Source onto a Target
+type Replicator struct {
+	source      Source
+	target      Target
+	mapping     *Mapping
+	mappingPath string
+}
+
+// New builds a Replicator. If mappingPath is not empty, the mapping is loaded from that file, so entities a
+// previous run already created are skipped, and saved back to it after every entity is created.
+func New(src Source, dst Target, mappingPath string) (*Replicator, error) {
+	m := NewMapping()
+	if mappingPath != "" {
+		var err error
+		if m, err = LoadMapping(mappingPath); err != nil {
+			return nil, errors.Wrap(err, "encountered error loading mapping file")
+		}
+	}
+
+	r := &Replicator{
+		source:      src,
+		target:      dst,
+		mapping:     m,
+		mappingPath: mappingPath,
+	}
+	return r, nil
+}
+
+// Mapping returns the source to target ID mapping built up so far
+func (r *Replicator) Mapping() *Mapping {
+	return r.mapping
+}
+
+func (r *Replicator) record(im IDMap, sourceID, targetID uint64) error {
+	im[sourceID] = targetID
+	if r.mappingPath == "" {
+		return nil
+	}
+	return errors.Wrap(r.mapping.Save(r.mappingPath), "encountered error saving mapping file")
+}
+
+// Run replicates every product root (with its options, option values and products) and every discount that
+// is not yet in the mapping
+func (r *Replicator) Run() error {
+	roots, err := r.source.GetAllProductRoots()
+	if err != nil {
+		return errors.Wrap(err, "encountered error listing source product roots")
+	}
+	for _, listed := range roots {
+		root, err := r.source.GetProductRoot(listed.ID)
+		if err != nil {
+			return errors.Wrapf(err, "encountered error reading source product root %d", listed.ID)
+		}
+		if err := r.replicateRoot(root); err != nil {
+			return err
+		}
+	}
+
+	discounts, err := r.source.GetAllDiscounts()
+	if err != nil {
+		return errors.Wrap(err, "encountered error listing source discounts")
+	}
+	for _, d := range discounts {
+		if _, ok := r.mapping.Discounts[d.ID]; ok {
+			continue
+		}
+		created, err := r.target.CreateDiscount(discountCreationInput(d))
+		if err != nil {
+			return errors.Wrapf(err, "encountered error creating discount %d", d.ID)
+		}
+		if err := r.record(r.mapping.Discounts, d.ID, created.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Replicator) replicateRoot(root *models.ProductRoot) error {
+	targetRootID, ok := r.mapping.ProductRoots[root.ID]
+	if !ok {
+		created, err := r.target.CreateProductRoot(rootCreationInput(*root))
+		if err != nil {
+			return errors.Wrapf(err, "encountered error creating product root %d", root.ID)
+		}
+		targetRootID = created.ID
+		if err := r.record(r.mapping.ProductRoots, root.ID, targetRootID); err != nil {
+			return err
+		}
+	}
+
+	for _, o := range root.Options {
+		if err := r.replicateOption(targetRootID, o); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range root.Products {
+		if _, ok := r.mapping.Products[p.ID]; ok {
+			continue
+		}
+		created, err := r.target.CreateProductRootVariant(targetRootID, productCreationInput(p))
+		if err != nil {
+			return errors.Wrapf(err, "encountered error creating product %q", p.SKU)
+		}
+		if err := r.record(r.mapping.Products, p.ID, created.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Replicator) replicateOption(targetRootID uint64, o models.ProductOption) error {
+	targetOptionID, ok := r.mapping.ProductOptions[o.ID]
+	if !ok {
+		no := models.ProductOptionCreationInput{Name: o.Name}
+		for _, v := range o.Values {
+			no.Values = append(no.Values, v.Value)
+		}
+
+		created, err := r.target.CreateProductOption(targetRootID, no)
+		if err != nil {
+			return errors.Wrapf(err, "encountered error creating product option %d", o.ID)
+		}
+		targetOptionID = created.ID
+		if err := r.record(r.mapping.ProductOptions, o.ID, targetOptionID); err != nil {
+			return err
+		}
+
+		// the values were created along with the option, so they only need matching up by value
+		createdValues := map[string]uint64{}
+		for _, v := range created.Values {
+			createdValues[v.Value] = v.ID
+		}
+		for _, v := range o.Values {
+			if id, ok := createdValues[v.Value]; ok {
+				if err := r.record(r.mapping.ProductOptionValues, v.ID, id); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, v := range o.Values {
+		if _, ok := r.mapping.ProductOptionValues[v.ID]; ok {
+			continue
+		}
+		created, err := r.target.CreateProductOptionValue(targetOptionID, models.ProductOptionValueCreationInput{Value: v.Value})
+		if err != nil {
+			return errors.Wrapf(err, "encountered error creating product option value %d", v.ID)
+		}
+		if err := r.record(r.mapping.ProductOptionValues, v.ID, created.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func rootCreationInput(root models.ProductRoot) dairyclient.ProductRootCreationInput {
+	nr := dairyclient.ProductRootCreationInput{
+		Name:               root.Name,
+		Subtitle:           root.Subtitle,
+		Description:        root.Description,
+		SKUPrefix:          root.SKUPrefix,
+		Manufacturer:       root.Manufacturer,
+		Brand:              root.Brand,
+		Taxable:            root.Taxable,
+		Cost:               root.Cost,
+		ProductWeight:      root.ProductWeight,
+		ProductHeight:      root.ProductHeight,
+		ProductWidth:       root.ProductWidth,
+		ProductLength:      root.ProductLength,
+		PackageWeight:      root.PackageWeight,
+		PackageHeight:      root.PackageHeight,
+		PackageWidth:       root.PackageWidth,
+		PackageLength:      root.PackageLength,
+		QuantityPerPackage: root.QuantityPerPackage,
+	}
+	if !root.AvailableOn.IsZero() {
+		availableOn := root.AvailableOn
+		nr.AvailableOn = &availableOn
+	}
+	return nr
+}
+
+func productCreationInput(p models.Product) models.ProductCreationInput {
+	np := models.ProductCreationInput{
+		Name:               p.Name,
+		Subtitle:           p.Subtitle,
+		Description:        p.Description,
+		SKU:                p.SKU,
+		UPC:                p.UPC,
+		Manufacturer:       p.Manufacturer,
+		Brand:              p.Brand,
+		Quantity:           p.Quantity,
+		Taxable:            p.Taxable,
+		Price:              p.Price,
+		OnSale:             p.OnSale,
+		SalePrice:          p.SalePrice,
+		Cost:               p.Cost,
+		ProductWeight:      p.ProductWeight,
+		ProductHeight:      p.ProductHeight,
+		ProductWidth:       p.ProductWidth,
+		ProductLength:      p.ProductLength,
+		PackageWeight:      p.PackageWeight,
+		PackageHeight:      p.PackageHeight,
+		PackageWidth:       p.PackageWidth,
+		PackageLength:      p.PackageLength,
+		QuantityPerPackage: p.QuantityPerPackage,
+	}
+	if !p.AvailableOn.IsZero() {
+		np.AvailableOn = &models.Dairytime{Time: p.AvailableOn}
+	}
+	return np
+}
+
+func discountCreationInput(d models.Discount) models.DiscountCreationInput {
+	nd := models.DiscountCreationInput{
+		Name:          d.Name,
+		DiscountType:  d.DiscountType,
+		Amount:        d.Amount,
+		RequiresCode:  d.RequiresCode,
+		Code:          d.Code,
+		LimitedUse:    d.LimitedUse,
+		NumberOfUses:  d.NumberOfUses,
+		LoginRequired: d.LoginRequired,
+	}
+	if !d.StartsOn.IsZero() {
+		nd.StartsOn = &models.Dairytime{Time: d.StartsOn}
+	}
+	if d.ExpiresOn != nil && !d.ExpiresOn.Time.IsZero() {
+		nd.ExpiresOn = &models.Dairytime{Time: d.ExpiresOn.Time}
+	}
+	return nd
+}
@@ -0,0 +1,335 @@
+package replicate_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dairycart/dairyclient/v1"
+	"github.com/dairycart/dairyclient/v1/replicate"
+	"github.com/dairycart/dairymodels/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ replicate.Source = (*dairyclient.V1Client)(nil)
+	_ replicate.Target = (*dairyclient.V1Client)(nil)
+)
+
+var exampleTime = time.Date(2017, 12, 10, 15, 58, 43, 0, time.UTC)
+
+// fakeStore is an in-memory store that hands out IDs starting from a configurable offset, so that source and
+// target IDs never line up by accident
+type fakeStore struct {
+	nextID    uint64
+	roots     []*models.ProductRoot
+	discounts []*models.Discount
+
+	// failAfter makes creates fail once this many have succeeded, when it is positive
+	failAfter int
+	creates   int
+}
+
+func newFakeStore(firstID uint64) *fakeStore {
+	return &fakeStore{nextID: firstID}
+}
+
+func (fs *fakeStore) id() uint64 {
+	fs.nextID++
+	return fs.nextID
+}
+
+func (fs *fakeStore) create() error {
+	if fs.failAfter > 0 && fs.creates >= fs.failAfter {
+		return errors.New("store is down")
+	}
+	fs.creates++
+	return nil
+}
+
+func (fs *fakeStore) GetAllProductRoots() ([]models.ProductRoot, error) {
+	var out []models.ProductRoot
+	for _, r := range fs.roots {
+		out = append(out, *r)
+	}
+	return out, nil
+}
+
+func (fs *fakeStore) GetProductRoot(rootID uint64) (*models.ProductRoot, error) {
+	for _, r := range fs.roots {
+		if r.ID == rootID {
+			out := *r
+			return &out, nil
+		}
+	}
+	return nil, &dairyclient.ClientError{FromAPI: &models.ErrorResponse{Status: 404, Message: "not found"}}
+}
+
+func (fs *fakeStore) GetAllDiscounts() ([]models.Discount, error) {
+	var out []models.Discount
+	for _, d := range fs.discounts {
+		out = append(out, *d)
+	}
+	return out, nil
+}
+
+func (fs *fakeStore) GetDiscountByID(discountID uint64) (*models.Discount, error) {
+	for _, d := range fs.discounts {
+		if d.ID == discountID {
+			out := *d
+			return &out, nil
+		}
+	}
+	return nil, &dairyclient.ClientError{FromAPI: &models.ErrorResponse{Status: 404, Message: "not found"}}
+}
+
+func (fs *fakeStore) root(id uint64) *models.ProductRoot {
+	for _, r := range fs.roots {
+		if r.ID == id {
+			return r
+		}
+	}
+	return nil
+}
+
+func (fs *fakeStore) CreateProductRoot(nr dairyclient.ProductRootCreationInput) (*models.ProductRoot, error) {
+	if err := fs.create(); err != nil {
+		return nil, err
+	}
+	r := &models.ProductRoot{
+		ID:           fs.id(),
+		Name:         nr.Name,
+		SKUPrefix:    nr.SKUPrefix,
+		Brand:        nr.Brand,
+		Cost:         nr.Cost,
+		Manufacturer: nr.Manufacturer,
+		CreatedOn:    time.Now(),
+	}
+	if nr.AvailableOn != nil {
+		r.AvailableOn = *nr.AvailableOn
+	}
+	fs.roots = append(fs.roots, r)
+	return r, nil
+}
+
+func (fs *fakeStore) CreateProductOption(rootID uint64, no models.ProductOptionCreationInput) (*models.ProductOption, error) {
+	if err := fs.create(); err != nil {
+		return nil, err
+	}
+	o := models.ProductOption{ID: fs.id(), Name: no.Name, ProductRootID: rootID}
+	for _, v := range no.Values {
+		o.Values = append(o.Values, models.ProductOptionValue{ID: fs.id(), ProductOptionID: o.ID, Value: v})
+	}
+	r := fs.root(rootID)
+	r.Options = append(r.Options, o)
+	return &o, nil
+}
+
+func (fs *fakeStore) CreateProductOptionValue(optionID uint64, nv models.ProductOptionValueCreationInput) (*models.ProductOptionValue, error) {
+	if err := fs.create(); err != nil {
+		return nil, err
+	}
+	for _, r := range fs.roots {
+		for i := range r.Options {
+			if r.Options[i].ID == optionID {
+				v := models.ProductOptionValue{ID: fs.id(), ProductOptionID: optionID, Value: nv.Value}
+				r.Options[i].Values = append(r.Options[i].Values, v)
+				return &v, nil
+			}
+		}
+	}
+	return nil, errors.New("no such option")
+}
+
+func (fs *fakeStore) CreateProductRootVariant(rootID uint64, np models.ProductCreationInput) (*models.Product, error) {
+	if err := fs.create(); err != nil {
+		return nil, err
+	}
+	p := models.Product{
+		ID:            fs.id(),
+		ProductRootID: rootID,
+		Name:          np.Name,
+		SKU:           np.SKU,
+		Price:         np.Price,
+		Quantity:      np.Quantity,
+	}
+	if np.AvailableOn != nil {
+		p.AvailableOn = np.AvailableOn.Time
+	}
+	r := fs.root(rootID)
+	r.Products = append(r.Products, p)
+	return &p, nil
+}
+
+func (fs *fakeStore) CreateDiscount(nd models.DiscountCreationInput) (*models.Discount, error) {
+	if err := fs.create(); err != nil {
+		return nil, err
+	}
+	d := &models.Discount{
+		ID:           fs.id(),
+		Name:         nd.Name,
+		DiscountType: nd.DiscountType,
+		Amount:       nd.Amount,
+		RequiresCode: nd.RequiresCode,
+		Code:         nd.Code,
+	}
+	if nd.StartsOn != nil {
+		d.StartsOn = nd.StartsOn.Time
+	}
+	fs.discounts = append(fs.discounts, d)
+	return d, nil
+}
+
+func buildExampleSource() *fakeStore {
+	src := newFakeStore(0)
+	src.roots = []*models.ProductRoot{
+		{
+			ID:          1,
+			Name:        "T-Shirt",
+			SKUPrefix:   "t-shirt",
+			Brand:       "Your Favorite Band",
+			AvailableOn: exampleTime,
+			Options: []models.ProductOption{
+				{ID: 2, Name: "color", ProductRootID: 1, Values: []models.ProductOptionValue{
+					{ID: 3, ProductOptionID: 2, Value: "red"},
+					{ID: 4, ProductOptionID: 2, Value: "blue"},
+				}},
+			},
+			Products: []models.Product{
+				{ID: 5, ProductRootID: 1, Name: "T-Shirt", SKU: "t-shirt-red", Price: 20, Quantity: 3, AvailableOn: exampleTime},
+				{ID: 6, ProductRootID: 1, Name: "T-Shirt", SKU: "t-shirt-blue", Price: 20, Quantity: 7, AvailableOn: exampleTime},
+			},
+		},
+		{ID: 7, Name: "Sticker", SKUPrefix: "sticker"},
+	}
+	src.discounts = []*models.Discount{
+		{ID: 8, Name: "10 percent off", DiscountType: "percentage", Amount: 10, StartsOn: exampleTime, RequiresCode: true, Code: "TENOFF"},
+	}
+	return src
+}
+
+func TestReplicator(t *testing.T) {
+	t.Run("normal usage", func(*testing.T) {
+		src, dst := buildExampleSource(), newFakeStore(100)
+
+		r, err := replicate.New(src, dst, "")
+		require.Nil(t, err)
+		require.Nil(t, r.Run())
+
+		m := r.Mapping()
+		assert.Len(t, m.ProductRoots, 2)
+		assert.Len(t, m.ProductOptions, 1)
+		assert.Len(t, m.ProductOptionValues, 2)
+		assert.Len(t, m.Products, 2)
+		assert.Len(t, m.Discounts, 1)
+
+		targetRoot := dst.root(m.ProductRoots[1])
+		require.NotNil(t, targetRoot)
+		assert.Equal(t, m.ProductOptions[2], targetRoot.Options[0].ID)
+		for _, p := range targetRoot.Products {
+			assert.Equal(t, targetRoot.ID, p.ProductRootID, "products should be linked to the target's root ID")
+		}
+
+		diffs, err := r.Verify()
+		require.Nil(t, err)
+		assert.Empty(t, diffs)
+	})
+
+	t.Run("is resumable", func(*testing.T) {
+		dir, err := ioutil.TempDir("", "replicate")
+		require.Nil(t, err)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "mapping.json")
+
+		src, dst := buildExampleSource(), newFakeStore(100)
+		dst.failAfter = 3
+
+		r, err := replicate.New(src, dst, path)
+		require.Nil(t, err)
+		assert.NotNil(t, r.Run(), "the run should fail once the target goes down")
+
+		dst.failAfter = 0
+		r, err = replicate.New(src, dst, path)
+		require.Nil(t, err)
+		require.Nil(t, r.Run())
+
+		assert.Len(t, dst.roots, 2, "nothing should have been created twice")
+		assert.Len(t, dst.root(r.Mapping().ProductRoots[1]).Products, 2)
+		assert.Len(t, dst.discounts, 1)
+
+		diffs, err := r.Verify()
+		require.Nil(t, err)
+		assert.Empty(t, diffs)
+	})
+
+	t.Run("verify reports differences", func(*testing.T) {
+		src, dst := buildExampleSource(), newFakeStore(100)
+
+		r, err := replicate.New(src, dst, "")
+		require.Nil(t, err)
+		require.Nil(t, r.Run())
+
+		dst.root(r.Mapping().ProductRoots[1]).Products[0].Price = 25
+		dst.discounts = nil
+
+		diffs, err := r.Verify()
+		require.Nil(t, err)
+		require.Len(t, diffs, 2)
+
+		assert.Equal(t, "product", diffs[0].Entity)
+		assert.Equal(t, uint64(5), diffs[0].SourceID)
+		assert.Equal(t, "Price", diffs[0].Field)
+		assert.Equal(t, float32(20), diffs[0].Source)
+		assert.Equal(t, float32(25), diffs[0].Target)
+
+		assert.Equal(t, "discount", diffs[1].Entity)
+		assert.Empty(t, diffs[1].Field)
+		assert.Equal(t, "discount 8 is missing from the target", diffs[1].String())
+	})
+}
+
+func TestLoadMapping(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replicate")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	t.Run("missing file", func(*testing.T) {
+		m, err := replicate.LoadMapping(filepath.Join(dir, "nope.json"))
+		require.Nil(t, err)
+		assert.Empty(t, m.Products)
+	})
+
+	t.Run("round trip", func(*testing.T) {
+		path := filepath.Join(dir, "mapping.json")
+		m := replicate.NewMapping()
+		m.Products[1] = 101
+
+		require.Nil(t, m.Save(path))
+		loaded, err := replicate.LoadMapping(path)
+		require.Nil(t, err)
+		assert.Equal(t, m, loaded)
+	})
+
+	t.Run("partial file", func(*testing.T) {
+		path := filepath.Join(dir, "partial.json")
+		require.Nil(t, ioutil.WriteFile(path, []byte(`{"products": {"1": 101}}`), 0600))
+
+		m, err := replicate.LoadMapping(path)
+		require.Nil(t, err)
+		assert.Equal(t, uint64(101), m.Products[1])
+		assert.NotNil(t, m.Discounts)
+	})
+
+	t.Run("invalid file", func(*testing.T) {
+		path := filepath.Join(dir, "invalid.json")
+		require.Nil(t, ioutil.WriteFile(path, []byte(`{"invalid lol}`), 0600))
+
+		_, err := replicate.LoadMapping(path)
+		assert.NotNil(t, err)
+	})
+}
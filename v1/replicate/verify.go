@@ -0,0 +1,196 @@
+package replicate
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/dairycart/dairyclient/v1"
+	"github.com/dairycart/dairymodels/v1"
+
+	"github.com/pkg/errors"
+)
+
+// Difference describes one way in which the target's copy of an entity differs from the source's
+type Difference struct {
+	Entity   string
+	SourceID uint64
+	TargetID uint64
+
+	// Field is empty when the entity is missing from the target altogether
+	Field  string
+	Source interface{}
+	Target interface{}
+}
+
+func (d Difference) String() string {
+	if d.Field == "" {
+		return fmt.Sprintf("%s %d is missing from the target", d.Entity, d.SourceID)
+	}
+	return fmt.Sprintf("%s %d (target %d): %s is %v on the source but %v on the target", d.Entity, d.SourceID, d.TargetID, d.Field, d.Source, d.Target)
+}
+
+// ignoredFields are IDs, timestamps and children, none of which are expected to match between stores
+var ignoredFields = map[string]bool{
+	"ID":              true,
+	"ProductRootID":   true,
+	"ProductOptionID": true,
+	"CreatedOn":       true,
+	"UpdatedOn":       true,
+	"ArchivedOn":      true,
+	"Options":         true,
+	"Products":        true,
+	"Values":          true,
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// compareFields reports every exported field, other than the ignored ones, that differs between two entities
+// of the same type
+func compareFields(entity string, sourceID, targetID uint64, src, dst interface{}) []Difference {
+	var diffs []Difference
+	sv, dv := reflect.ValueOf(src), reflect.ValueOf(dst)
+	for i := 0; i < sv.NumField(); i++ {
+		f := sv.Type().Field(i)
+		if f.PkgPath != "" || ignoredFields[f.Name] {
+			continue
+		}
+
+		a, b := sv.Field(i), dv.Field(i)
+		var equal bool
+		if f.Type == timeType {
+			equal = a.Interface().(time.Time).Equal(b.Interface().(time.Time))
+		} else {
+			equal = reflect.DeepEqual(a.Interface(), b.Interface())
+		}
+
+		if !equal {
+			diffs = append(diffs, Difference{
+				Entity:   entity,
+				SourceID: sourceID,
+				TargetID: targetID,
+				Field:    f.Name,
+				Source:   a.Interface(),
+				Target:   b.Interface(),
+			})
+		}
+	}
+	return diffs
+}
+
+func isNotFound(err error) bool {
+	ce, ok := errors.Cause(err).(*dairyclient.ClientError)
+	return ok && ce.FromAPI != nil && ce.FromAPI.Status == http.StatusNotFound
+}
+
+// Verify compares every product root, product option, option value, product and discount on the source with
+// its counterpart on the target, as found through the mapping, and returns the differences. Entities that were
+// never replicated, or that have since been deleted from the target, are reported as missing.
+func (r *Replicator) Verify() ([]Difference, error) {
+	var diffs []Difference
+
+	roots, err := r.source.GetAllProductRoots()
+	if err != nil {
+		return nil, errors.Wrap(err, "encountered error listing source product roots")
+	}
+	for _, listed := range roots {
+		src, err := r.source.GetProductRoot(listed.ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "encountered error reading source product root %d", listed.ID)
+		}
+
+		targetID, ok := r.mapping.ProductRoots[src.ID]
+		var dst *models.ProductRoot
+		if ok {
+			dst, err = r.target.GetProductRoot(targetID)
+			if err != nil && !isNotFound(err) {
+				return nil, errors.Wrapf(err, "encountered error reading target product root %d", targetID)
+			}
+		}
+		if dst == nil {
+			diffs = append(diffs, Difference{Entity: "product_root", SourceID: src.ID})
+			continue
+		}
+
+		diffs = append(diffs, compareFields("product_root", src.ID, dst.ID, *src, *dst)...)
+		diffs = append(diffs, r.verifyOptions(src.Options, dst.Options)...)
+		diffs = append(diffs, r.verifyProducts(src.Products, dst.Products)...)
+	}
+
+	discounts, err := r.source.GetAllDiscounts()
+	if err != nil {
+		return nil, errors.Wrap(err, "encountered error listing source discounts")
+	}
+	for _, src := range discounts {
+		targetID, ok := r.mapping.Discounts[src.ID]
+		var dst *models.Discount
+		if ok {
+			dst, err = r.target.GetDiscountByID(targetID)
+			if err != nil && !isNotFound(err) {
+				return nil, errors.Wrapf(err, "encountered error reading target discount %d", targetID)
+			}
+		}
+		if dst == nil {
+			diffs = append(diffs, Difference{Entity: "discount", SourceID: src.ID})
+			continue
+		}
+
+		diffs = append(diffs, compareFields("discount", src.ID, dst.ID, src, *dst)...)
+	}
+
+	return diffs, nil
+}
+
+func (r *Replicator) verifyOptions(src, dst []models.ProductOption) []Difference {
+	var diffs []Difference
+
+	byID := map[uint64]models.ProductOption{}
+	for _, o := range dst {
+		byID[o.ID] = o
+	}
+
+	for _, so := range src {
+		do, ok := byID[r.mapping.ProductOptions[so.ID]]
+		if !ok {
+			diffs = append(diffs, Difference{Entity: "product_option", SourceID: so.ID})
+			continue
+		}
+		diffs = append(diffs, compareFields("product_option", so.ID, do.ID, so, do)...)
+
+		values := map[uint64]models.ProductOptionValue{}
+		for _, v := range do.Values {
+			values[v.ID] = v
+		}
+		for _, sv := range so.Values {
+			dv, ok := values[r.mapping.ProductOptionValues[sv.ID]]
+			if !ok {
+				diffs = append(diffs, Difference{Entity: "product_option_value", SourceID: sv.ID})
+				continue
+			}
+			diffs = append(diffs, compareFields("product_option_value", sv.ID, dv.ID, sv, dv)...)
+		}
+	}
+
+	return diffs
+}
+
+func (r *Replicator) verifyProducts(src, dst []models.Product) []Difference {
+	var diffs []Difference
+
+	byID := map[uint64]models.Product{}
+	for _, p := range dst {
+		byID[p.ID] = p
+	}
+
+	for _, sp := range src {
+		dp, ok := byID[r.mapping.Products[sp.ID]]
+		if !ok {
+			diffs = append(diffs, Difference{Entity: "product", SourceID: sp.ID})
+			continue
+		}
+		diffs = append(diffs, compareFields("product", sp.ID, dp.ID, sp, dp)...)
+	}
+
+	return diffs
+}
@@ -0,0 +1,248 @@
+// Package searchindex keeps an in-memory full-text index of a Dairycart store's products and product roots, for
+// fast, typo tolerant search from admin tools. An Index is built once from the store's list endpoints and then
+// kept current by refreshes that reread the active catalog and reindex only what changed since the last one.
+package searchindex
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dairycart/dairyclient/v1/changefeed"
+	"github.com/dairycart/dairymodels/v1"
+
+	"github.com/pkg/errors"
+)
+
+// Kind identifies the kind of catalog entity a search result refers to
+type Kind string
+
+// These are the kinds of entity an Index holds
+const (
+	ProductKind     Kind = "product"
+	ProductRootKind Kind = "product_root"
+)
+
+// Source is the subset of the client an Index reads from
+type Source interface {
+	GetAllProducts() ([]models.Product, error)
+	GetAllProductRoots() ([]models.ProductRoot, error)
+}
+
+type docKey struct {
+	kind Kind
+	id   uint64
+}
+
+// document is what the index remembers about one entity, with the text of each searchable field
+type document struct {
+	key    docKey
+	sku    string
+	name   string
+	fields map[field]string
+}
+
+// Index is an in-memory inverted index over a store's catalog. It is safe for concurrent use.
+type Index struct {
+	source Source
+
+	// MinBackoff is how long Run waits before the first retry of a failed refresh. It defaults to
+	// changefeed.DefaultMinBackoff.
+	MinBackoff time.Duration
+	// OnError, if set, is called by Run with every failed refresh before it is retried
+	OnError func(error)
+
+	mu       sync.RWMutex
+	docs     map[docKey]*document
+	postings map[string]map[docKey]float64
+	synced   time.Time
+}
+
+// New builds an empty Index over src. Call Build before searching it.
+func New(src Source) *Index {
+	return &Index{
+		source:     src,
+		MinBackoff: changefeed.DefaultMinBackoff,
+		docs:       map[docKey]*document{},
+		postings:   map[string]map[docKey]float64{},
+	}
+}
+
+// Len returns how many products and product roots are indexed
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+// Build reads every active product and product root from the store, replacing anything already indexed
+func (ix *Index) Build() error {
+	products, roots, err := ix.list()
+	if err != nil {
+		return err
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.docs = map[docKey]*document{}
+	ix.postings = map[string]map[docKey]float64{}
+	ix.synced = time.Time{}
+	ix.apply(products, roots)
+	return nil
+}
+
+// Refresh rereads the store's active products and product roots and updates the index to match. Only entities
+// created or updated since the last Build or Refresh are reindexed, and those no longer listed are dropped.
+func (ix *Index) Refresh() error {
+	ix.mu.RLock()
+	since := ix.synced
+	ix.mu.RUnlock()
+	if since.IsZero() {
+		return ix.Build()
+	}
+
+	products, roots, err := ix.list()
+	if err != nil {
+		return err
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.apply(products, roots)
+	return nil
+}
+
+// Run refreshes the index every interval until ctx is cancelled. A failed refresh is passed to OnError, if it is
+// set, and retried after a backoff that starts at MinBackoff and doubles up to the interval. Run does not build the
+// index first, so searches made before the first refresh see whatever Build last read.
+func (ix *Index) Run(ctx context.Context, interval time.Duration) error {
+	backoff, wait := time.Duration(0), interval
+	for {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		if err := ix.Refresh(); err != nil {
+			if ix.OnError != nil {
+				ix.OnError(err)
+			}
+			backoff = min(max(backoff*2, ix.MinBackoff), interval)
+			wait = backoff
+		} else {
+			backoff, wait = 0, interval
+		}
+	}
+}
+
+func (ix *Index) list() ([]models.Product, []models.ProductRoot, error) {
+	products, err := ix.source.GetAllProducts()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "encountered error listing products")
+	}
+	roots, err := ix.source.GetAllProductRoots()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "encountered error listing product roots")
+	}
+	return products, roots, nil
+}
+
+// known returns the IDs of every indexed entity of the given kind. The caller must hold a lock.
+func (ix *Index) known(kind Kind) map[uint64]bool {
+	ids := map[uint64]bool{}
+	for key := range ix.docs {
+		if key.kind == kind {
+			ids[key.id] = true
+		}
+	}
+	return ids
+}
+
+// apply indexes the listed entities that changed since the sync point, removes indexed ones that are no longer
+// listed, and advances the sync point to the latest change seen. The caller must hold the write lock.
+func (ix *Index) apply(products []models.Product, roots []models.ProductRoot) {
+	since := ix.synced
+
+	pd := changefeed.Detect(since, ix.known(ProductKind), products, changefeed.ProductStamp)
+	for _, id := range pd.Archived {
+		ix.remove(docKey{kind: ProductKind, id: id})
+	}
+	for _, c := range pd.Changed {
+		p := c.Entity
+		ix.advance(c.At)
+		ix.add(&document{
+			key:  docKey{kind: ProductKind, id: p.ID},
+			sku:  p.SKU,
+			name: p.Name,
+			fields: map[field]string{
+				skuField:         p.SKU,
+				nameField:        p.Name,
+				brandField:       p.Brand + " " + p.Manufacturer,
+				subtitleField:    p.Subtitle + " " + p.OptionSummary,
+				descriptionField: p.Description,
+			},
+		})
+	}
+
+	rd := changefeed.Detect(since, ix.known(ProductRootKind), roots, changefeed.ProductRootStamp)
+	for _, id := range rd.Archived {
+		ix.remove(docKey{kind: ProductRootKind, id: id})
+	}
+	for _, c := range rd.Changed {
+		r := c.Entity
+		ix.advance(c.At)
+		ix.add(&document{
+			key:  docKey{kind: ProductRootKind, id: r.ID},
+			sku:  r.SKUPrefix,
+			name: r.Name,
+			fields: map[field]string{
+				skuField:         r.SKUPrefix,
+				nameField:        r.Name,
+				brandField:       r.Brand + " " + r.Manufacturer,
+				subtitleField:    r.Subtitle,
+				descriptionField: r.Description,
+			},
+		})
+	}
+}
+
+func (ix *Index) advance(at time.Time) {
+	if at.After(ix.synced) {
+		ix.synced = at
+	}
+}
+
+func (ix *Index) add(doc *document) {
+	ix.remove(doc.key)
+	ix.docs[doc.key] = doc
+	for f, text := range doc.fields {
+		for _, term := range f.terms(text) {
+			if ix.postings[term] == nil {
+				ix.postings[term] = map[docKey]float64{}
+			}
+			// a term's weight in a document is that of the most important field it appears in
+			if w := fieldWeights[f]; w > ix.postings[term][doc.key] {
+				ix.postings[term][doc.key] = w
+			}
+		}
+	}
+}
+
+func (ix *Index) remove(key docKey) {
+	doc, ok := ix.docs[key]
+	if !ok {
+		return
+	}
+	delete(ix.docs, key)
+	for f, text := range doc.fields {
+		for _, term := range f.terms(text) {
+			delete(ix.postings[term], key)
+			if len(ix.postings[term]) == 0 {
+				delete(ix.postings, term)
+			}
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package searchindex
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// field identifies which part of an entity a term came from
+type field int
+
+const (
+	skuField field = iota
+	nameField
+	brandField
+	subtitleField
+	descriptionField
+)
+
+// fieldWeights rank matches by where they were found: a SKU or name match says much more than one buried in a
+// description
+var fieldWeights = map[field]float64{
+	skuField:         5,
+	nameField:        3,
+	brandField:       2,
+	subtitleField:    1.5,
+	descriptionField: 1,
+}
+
+// These scale a term's weight by how closely it matched the query term
+const (
+	exactMatch  = 1.0
+	prefixMatch = 0.75
+	fuzzyMatch  = 0.5
+)
+
+// tokenize lowercases text and splits it on anything that isn't a letter or digit
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// terms returns the index terms for a field's text. SKUs are also indexed whole, so that pasting one finds it
+// exactly.
+func (f field) terms(text string) []string {
+	terms := tokenize(text)
+	if f == skuField && text != "" {
+		terms = append(terms, strings.ToLower(text))
+	}
+	return terms
+}
+
+// maxEdits is how many typos a query term of a given length may contain. Short terms must match exactly, since
+// a single edit can turn them into a different word altogether.
+func maxEdits(length int) int {
+	switch {
+	case length < 4:
+		return 0
+	case length < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// matchFactor reports how well an index term matches a query term, or zero if it doesn't
+func matchFactor(query, term string) float64 {
+	if query == term {
+		return exactMatch
+	}
+	if len(query) >= 2 && strings.HasPrefix(term, query) {
+		return prefixMatch
+	}
+	allowed := maxEdits(len([]rune(query)))
+	if allowed == 0 {
+		return 0
+	}
+	if d := editDistance(query, term, allowed); d <= allowed {
+		return fuzzyMatch / float64(d)
+	}
+	return 0
+}
+
+// editDistance returns the Levenshtein distance between a and b, or limit+1 once it is known to exceed limit
+func editDistance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if diff := len(ra) - len(rb); diff > limit || -diff > limit {
+		return limit + 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// Result is one entity matching a search, with the score it was ranked by
+type Result struct {
+	Kind  Kind
+	ID    uint64
+	SKU   string
+	Name  string
+	Score float64
+}
+
+// Search returns the products and product roots matching every word of query, best first, and at most limit of
+// them if limit is positive. Words match exactly, as a prefix, or with a typo or two if they are long enough.
+func (ix *Index) Search(query string, limit int) []Result {
+	words := tokenize(query)
+	if len(words) == 0 {
+		return nil
+	}
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	var scores map[docKey]float64
+	for _, word := range words {
+		matched := map[docKey]float64{}
+		for term, docs := range ix.postings {
+			factor := matchFactor(word, term)
+			if factor == 0 {
+				continue
+			}
+			for key, weight := range docs {
+				if s := weight * factor; s > matched[key] {
+					matched[key] = s
+				}
+			}
+		}
+
+		if scores == nil {
+			scores = matched
+			continue
+		}
+		for key := range scores {
+			if s, ok := matched[key]; ok {
+				scores[key] += s
+			} else {
+				delete(scores, key)
+			}
+		}
+	}
+
+	// a query that is a whole SKU outranks everything that merely shares its parts
+	for key := range scores {
+		if sku := ix.docs[key].sku; sku != "" && strings.EqualFold(sku, strings.TrimSpace(query)) {
+			scores[key] += fieldWeights[skuField]
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for key, score := range scores {
+		doc := ix.docs[key]
+		results = append(results, Result{Kind: key.kind, ID: key.id, SKU: doc.sku, Name: doc.name, Score: score})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].Name != results[j].Name {
+			return results[i].Name < results[j].Name
+		}
+		return results[i].ID < results[j].ID
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
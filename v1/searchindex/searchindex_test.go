@@ -0,0 +1,165 @@
+package searchindex_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dairycart/dairyclient/v1"
+	"github.com/dairycart/dairyclient/v1/searchindex"
+	"github.com/dairycart/dairymodels/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ searchindex.Source = (*dairyclient.V1Client)(nil)
+
+var baseTime = time.Date(2017, 12, 10, 15, 58, 43, 0, time.UTC)
+
+// fakeSource lists its active entities, failing while failures is above zero
+type fakeSource struct {
+	products []models.Product
+	roots    []models.ProductRoot
+	failures int
+}
+
+func (fs *fakeSource) GetAllProducts() ([]models.Product, error) {
+	if fs.failures > 0 {
+		fs.failures--
+		return nil, errors.New("arbitrary error")
+	}
+	var out []models.Product
+	for _, p := range fs.products {
+		if p.ArchivedOn == nil {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (fs *fakeSource) GetAllProductRoots() ([]models.ProductRoot, error) {
+	var out []models.ProductRoot
+	for _, r := range fs.roots {
+		if r.ArchivedOn == nil {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func buildCatalog() *fakeSource {
+	return &fakeSource{
+		products: []models.Product{
+			{ID: 1, SKU: "t-shirt-small-red", Name: "Band T-Shirt", Brand: "Your Favorite Band", Description: "cotton", CreatedOn: baseTime},
+			{ID: 2, SKU: "hoodie-large", Name: "Band Hoodie", Brand: "Your Favorite Band", Description: "warm fleece", CreatedOn: baseTime},
+			{ID: 3, SKU: "mug", Name: "Coffee Mug", Description: "holds a band t-shirt's worth of coffee", CreatedOn: baseTime},
+		},
+		roots: []models.ProductRoot{
+			{ID: 10, SKUPrefix: "t-shirt", Name: "Band T-Shirt", CreatedOn: baseTime},
+		},
+	}
+}
+
+func skus(results []searchindex.Result) []string {
+	var out []string
+	for _, r := range results {
+		out = append(out, r.SKU)
+	}
+	return out
+}
+
+func TestSearch(t *testing.T) {
+	ix := searchindex.New(buildCatalog())
+	require.Nil(t, ix.Build())
+	assert.Equal(t, 4, ix.Len())
+
+	testCases := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{name: "name word", query: "hoodie", expected: []string{"hoodie-large"}},
+		{name: "every word must match", query: "band hoodie", expected: []string{"hoodie-large"}},
+		{name: "prefix", query: "hood", expected: []string{"hoodie-large"}},
+		{name: "typo", query: "hoddie", expected: []string{"hoodie-large"}},
+		{name: "too many typos", query: "cofeeeeee", expected: nil},
+		{name: "short words must be exact", query: "mig", expected: nil},
+		{name: "whole sku", query: "T-Shirt-Small-Red", expected: []string{"t-shirt-small-red"}},
+		{name: "description", query: "fleece", expected: []string{"hoodie-large"}},
+		{name: "name outranks description", query: "t-shirt", expected: []string{"t-shirt", "t-shirt-small-red", "mug"}},
+		{name: "empty query", query: " - ", expected: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, skus(ix.Search(tc.query, 0)))
+		})
+	}
+
+	t.Run("with limit", func(*testing.T) {
+		results := ix.Search("band", 2)
+		assert.Len(t, results, 2)
+		assert.Equal(t, searchindex.ProductRootKind, ix.Search("t-shirt", 1)[0].Kind)
+	})
+}
+
+func TestRefresh(t *testing.T) {
+	src := buildCatalog()
+	ix := searchindex.New(src)
+	require.Nil(t, ix.Build())
+
+	later := &models.Dairytime{Time: baseTime.Add(time.Minute)}
+	src.products[1].Name = "Band Zip Hoodie"
+	src.products[1].UpdatedOn = later
+	src.products = append(src.products, models.Product{ID: 4, SKU: "poster", Name: "Tour Poster", CreatedOn: later.Time})
+	require.Nil(t, ix.Refresh())
+
+	assert.Equal(t, []string{"hoodie-large"}, skus(ix.Search("zip", 0)), "updated products should be reindexed")
+	assert.Equal(t, []string{"poster"}, skus(ix.Search("poster", 0)), "new products should be added")
+	assert.Equal(t, 5, ix.Len())
+
+	t.Run("drops products no longer listed", func(*testing.T) {
+		src.products[2].ArchivedOn = later
+		require.Nil(t, ix.Refresh())
+
+		assert.Empty(t, ix.Search("mug", 0), "archived products should be dropped")
+		assert.Equal(t, 4, ix.Len())
+	})
+
+	t.Run("with source error", func(*testing.T) {
+		src.failures = 1
+		assert.NotNil(t, ix.Refresh())
+		assert.Equal(t, 4, ix.Len())
+	})
+}
+
+func TestRun(t *testing.T) {
+	t.Run("retries after source error", func(*testing.T) {
+		ix := searchindex.New(&fakeSource{products: buildCatalog().products, failures: 2})
+		ix.MinBackoff = time.Millisecond
+		var errs []error
+		ix.OnError = func(err error) { errs = append(errs, err) }
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- ix.Run(ctx, 10*time.Millisecond) }()
+
+		deadline := time.Now().Add(time.Second)
+		for ix.Len() != 3 {
+			require.True(t, time.Now().Before(deadline), "timed out waiting for a successful refresh")
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+		assert.Equal(t, context.Canceled, <-done)
+		assert.Len(t, errs, 2)
+	})
+
+	t.Run("stops when cancelled", func(*testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		ix := searchindex.New(buildCatalog())
+		assert.Equal(t, context.Canceled, ix.Run(ctx, time.Hour))
+	})
+}
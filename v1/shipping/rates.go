@@ -0,0 +1,98 @@
+package shipping
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+
+	"github.com/dairycart/dairyclient/v1/pricing"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrUnknownZone is returned when a rate table has no rates for a destination zone
+	ErrUnknownZone = errors.New("no rates for shipping zone")
+	// ErrOverweight is returned when a parcel is heavier than any rate in a table covers
+	ErrOverweight = errors.New("parcel exceeds the heaviest rate")
+)
+
+// Rater prices a parcel of a given billable weight for a destination zone. Packer.Quote calls it once per parcel,
+// with the billable weight already worked out from the parcel's box, so a Rater never sees dimensions.
+type Rater interface {
+	Rate(zone string, billableWeight float64) (float64, error)
+}
+
+// RateTier is the price of a parcel weighing up to MaxWeight
+type RateTier struct {
+	MaxWeight float64 `json:"max_weight"`
+	Rate      float64 `json:"rate"`
+}
+
+// RateTable is a Rater that prices parcels from weight tiers for each zone. Tiers may be listed in any order.
+type RateTable struct {
+	Zones map[string][]RateTier `json:"zones"`
+}
+
+// LoadRateTable reads a RateTable from a JSON file shaped like
+//
+//	{"zones": {"domestic": [{"max_weight": 1, "rate": 4.5}, {"max_weight": 5, "rate": 9}]}}
+func LoadRateTable(path string) (*RateTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rt := &RateTable{}
+	if err := json.NewDecoder(f).Decode(rt); err != nil {
+		return nil, errors.Wrap(err, "encountered error decoding rate table")
+	}
+	return rt, nil
+}
+
+// Rate returns the rate of the lightest tier that covers billableWeight
+func (rt *RateTable) Rate(zone string, billableWeight float64) (float64, error) {
+	tiers, ok := rt.Zones[zone]
+	if !ok {
+		return 0, errors.Wrapf(ErrUnknownZone, "zone %q", zone)
+	}
+
+	best := -1
+	for i, t := range tiers {
+		if t.MaxWeight >= billableWeight && (best < 0 || t.MaxWeight < tiers[best].MaxWeight) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return 0, errors.Wrapf(ErrOverweight, "%v in zone %q", billableWeight, zone)
+	}
+	return tiers[best].Rate, nil
+}
+
+// ParcelQuote is the cost of shipping one Parcel
+type ParcelQuote struct {
+	Parcel
+	Cost float64
+}
+
+// Quote is the cost of shipping a set of items, parcel by parcel
+type Quote struct {
+	Parcels []ParcelQuote
+	Total   float64
+}
+
+// Quote packs items and prices every resulting parcel for a destination zone
+func (pk Packer) Quote(items []pricing.Item, zone string, r Rater) (*Quote, error) {
+	q := &Quote{}
+	for _, p := range pk.Pack(items) {
+		cost, err := r.Rate(zone, p.BillableWeight)
+		if err != nil {
+			return nil, err
+		}
+		q.Parcels = append(q.Parcels, ParcelQuote{Parcel: p, Cost: cost})
+		q.Total += cost
+	}
+	q.Total = math.Round(q.Total*100) / 100
+	return q, nil
+}
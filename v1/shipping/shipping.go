@@ -0,0 +1,212 @@
+// Package shipping works out how a set of products ships: the dimensional weight carriers bill by, how the
+// products pack into a store's boxes, and what the resulting parcels cost under a local rate table. It works
+// entirely from the weights and dimensions on the models the client returns, so no request is made.
+//
+// The package has no opinion on units. Weights and lengths just need to be in the same units as the store's
+// product data, with a dimensional weight divisor to match.
+package shipping
+
+import (
+	"math"
+	"sort"
+
+	"github.com/dairycart/dairyclient/v1/pricing"
+	"github.com/dairycart/dairymodels/v1"
+)
+
+// These are common dimensional weight divisors
+const (
+	// DivisorInchesPounds is the divisor most carriers use for cubic inches to pounds
+	DivisorInchesPounds = 139
+	// DivisorInchesPoundsRetail is the divisor some carriers use for retail shipments in cubic inches to pounds
+	DivisorInchesPoundsRetail = 166
+	// DivisorCentimetersKilograms is the usual divisor for cubic centimeters to kilograms
+	DivisorCentimetersKilograms = 5000
+)
+
+// DimensionalWeight returns the weight a carrier bills a parcel of the given size as, regardless of what it
+// actually weighs
+func DimensionalWeight(length, width, height, divisor float64) float64 {
+	if divisor <= 0 {
+		return 0
+	}
+	return length * width * height / divisor
+}
+
+// BillableWeight returns whichever of a parcel's actual and dimensional weights is greater
+func BillableWeight(actual, dimensional float64) float64 {
+	return math.Max(actual, dimensional)
+}
+
+// Box is a shipping box a store packs products into
+type Box struct {
+	Name   string
+	Length float64
+	Width  float64
+	Height float64
+
+	// Weight is the empty box's own weight
+	Weight float64
+	// MaxWeight is the most the box may hold, contents included. Zero means no limit.
+	MaxWeight float64
+}
+
+func (b Box) volume() float64 {
+	return b.Length * b.Width * b.Height
+}
+
+// unit is one package of a product: quantity_per_package units of it, in its own packaging
+type unit struct {
+	sku      string
+	quantity uint32
+	dims     [3]float64
+	weight   float64
+}
+
+func (u unit) volume() float64 {
+	return u.dims[0] * u.dims[1] * u.dims[2]
+}
+
+// packageOf returns the size and weight of a product's package, falling back to the product's own size and
+// weight when it has no package dimensions
+func packageOf(p models.Product) (dims [3]float64, weight float64) {
+	if p.PackageLength > 0 && p.PackageWidth > 0 && p.PackageHeight > 0 {
+		dims = [3]float64{float64(p.PackageLength), float64(p.PackageWidth), float64(p.PackageHeight)}
+	} else {
+		dims = [3]float64{float64(p.ProductLength), float64(p.ProductWidth), float64(p.ProductHeight)}
+	}
+	weight = float64(p.PackageWeight)
+	if weight == 0 {
+		weight = float64(p.ProductWeight)
+	}
+	return dims, weight
+}
+
+// units splits items into the packages they ship in. A quantity that isn't a multiple of quantity_per_package
+// ships its remainder in one more package.
+func units(items []pricing.Item) []unit {
+	var out []unit
+	for _, i := range items {
+		perPackage := i.Product.QuantityPerPackage
+		if perPackage == 0 {
+			perPackage = 1
+		}
+		dims, weight := packageOf(i.Product)
+		for remaining := i.Quantity; remaining > 0; {
+			q := perPackage
+			if remaining < q {
+				q = remaining
+			}
+			out = append(out, unit{sku: i.Product.SKU, quantity: q, dims: dims, weight: weight})
+			remaining -= q
+		}
+	}
+	return out
+}
+
+// fits reports whether a package can go into a box in some orientation
+func fits(u unit, b Box) bool {
+	ud := u.dims
+	bd := [3]float64{b.Length, b.Width, b.Height}
+	sort.Float64s(ud[:])
+	sort.Float64s(bd[:])
+	return ud[0] <= bd[0] && ud[1] <= bd[1] && ud[2] <= bd[2]
+}
+
+// Contents is a quantity of one SKU in a Parcel
+type Contents struct {
+	SKU      string
+	Quantity uint32
+}
+
+// Parcel is one thing handed to a carrier: either a Box with products packed in it, or, when Box is nil, a
+// single product package shipped on its own because it fit no box
+type Parcel struct {
+	Box      *Box
+	Contents []Contents
+	Length   float64
+	Width    float64
+	Height   float64
+
+	Weight            float64
+	DimensionalWeight float64
+	BillableWeight    float64
+
+	usedVolume float64
+}
+
+func (p *Parcel) add(u unit) {
+	p.Weight += u.weight
+	p.usedVolume += u.volume()
+	for i := range p.Contents {
+		if p.Contents[i].SKU == u.sku {
+			p.Contents[i].Quantity += u.quantity
+			return
+		}
+	}
+	p.Contents = append(p.Contents, Contents{SKU: u.sku, Quantity: u.quantity})
+}
+
+func (p *Parcel) accepts(u unit) bool {
+	if p.Box == nil || !fits(u, *p.Box) {
+		return false
+	}
+	if p.Box.MaxWeight > 0 && p.Weight+u.weight > p.Box.MaxWeight {
+		return false
+	}
+	return p.usedVolume+u.volume() <= p.Box.volume()
+}
+
+// Packer packs products into a store's boxes
+type Packer struct {
+	// Boxes are the boxes available, tried smallest first when a new one is needed
+	Boxes []Box
+	// Divisor converts a parcel's volume into its dimensional weight
+	Divisor float64
+}
+
+// Pack packs items into parcels. It is a first-fit decreasing packer that checks each package fits a box's
+// dimensions and that the box's volume and weight limits aren't exceeded; it does not solve the exact 3D
+// arrangement, so it can occasionally pack more into a box than would physically fit.
+func (pk Packer) Pack(items []pricing.Item) []Parcel {
+	boxes := append([]Box(nil), pk.Boxes...)
+	sort.SliceStable(boxes, func(i, j int) bool { return boxes[i].volume() < boxes[j].volume() })
+
+	us := units(items)
+	sort.SliceStable(us, func(i, j int) bool { return us[i].volume() > us[j].volume() })
+
+	var parcels []*Parcel
+	for _, u := range us {
+		placed := false
+		for _, p := range parcels {
+			if p.accepts(u) {
+				p.add(u)
+				placed = true
+				break
+			}
+		}
+		if placed {
+			continue
+		}
+
+		p := &Parcel{Length: u.dims[0], Width: u.dims[1], Height: u.dims[2]}
+		for i := range boxes {
+			candidate := &Parcel{Box: &boxes[i], Weight: boxes[i].Weight}
+			if candidate.accepts(u) {
+				p = candidate
+				p.Length, p.Width, p.Height = p.Box.Length, p.Box.Width, p.Box.Height
+				break
+			}
+		}
+		p.add(u)
+		parcels = append(parcels, p)
+	}
+
+	out := make([]Parcel, 0, len(parcels))
+	for _, p := range parcels {
+		p.DimensionalWeight = DimensionalWeight(p.Length, p.Width, p.Height, pk.Divisor)
+		p.BillableWeight = BillableWeight(p.Weight, p.DimensionalWeight)
+		out = append(out, *p)
+	}
+	return out
+}
@@ -0,0 +1,149 @@
+package shipping_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dairycart/dairyclient/v1/pricing"
+	"github.com/dairycart/dairyclient/v1/shipping"
+	"github.com/dairycart/dairymodels/v1"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	tShirt = models.Product{
+		SKU:                "t-shirt",
+		ProductWeight:      0.5,
+		PackageLength:      10,
+		PackageWidth:       8,
+		PackageHeight:      1,
+		PackageWeight:      0.6,
+		QuantityPerPackage: 1,
+	}
+	// mugs come in packs of two
+	mug = models.Product{
+		SKU:                "mug",
+		PackageLength:      8,
+		PackageWidth:       4,
+		PackageHeight:      5,
+		PackageWeight:      2,
+		QuantityPerPackage: 2,
+	}
+	poster = models.Product{
+		SKU:           "poster",
+		ProductLength: 36,
+		ProductWidth:  3,
+		ProductHeight: 3,
+		ProductWeight: 0.4,
+	}
+
+	boxes = []shipping.Box{
+		{Name: "large", Length: 18, Width: 14, Height: 12, Weight: 1, MaxWeight: 30},
+		{Name: "small", Length: 12, Width: 10, Height: 4, Weight: 0.5, MaxWeight: 10},
+	}
+)
+
+func TestDimensionalWeight(t *testing.T) {
+	assert.Equal(t, 12.0, shipping.DimensionalWeight(12, 139, 1, shipping.DivisorInchesPounds))
+	assert.Equal(t, 0.0, shipping.DimensionalWeight(12, 12, 12, 0))
+	assert.Equal(t, 5.0, shipping.BillableWeight(5, 2))
+	assert.Equal(t, 7.0, shipping.BillableWeight(5, 7))
+}
+
+func TestPack(t *testing.T) {
+	pk := shipping.Packer{Boxes: boxes, Divisor: shipping.DivisorInchesPounds}
+
+	t.Run("fits in the smallest box", func(*testing.T) {
+		parcels := pk.Pack([]pricing.Item{{Product: tShirt, Quantity: 3}})
+		require.Len(t, parcels, 1)
+		assert.Equal(t, "small", parcels[0].Box.Name)
+		assert.Equal(t, []shipping.Contents{{SKU: "t-shirt", Quantity: 3}}, parcels[0].Contents)
+		assert.InDelta(t, 2.3, parcels[0].Weight, 0.001)
+		assert.InDelta(t, 480.0/139, parcels[0].DimensionalWeight, 0.001)
+		assert.InDelta(t, 3.453, parcels[0].BillableWeight, 0.001)
+	})
+
+	t.Run("packs by package quantity", func(*testing.T) {
+		parcels := pk.Pack([]pricing.Item{{Product: mug, Quantity: 3}})
+		require.Len(t, parcels, 1)
+		assert.Equal(t, []shipping.Contents{{SKU: "mug", Quantity: 3}}, parcels[0].Contents)
+		assert.InDelta(t, 4.5, parcels[0].Weight, 0.001, "three mugs ship as two packages")
+	})
+
+	t.Run("overflows into another box", func(*testing.T) {
+		parcels := pk.Pack([]pricing.Item{{Product: mug, Quantity: 10}})
+		require.Len(t, parcels, 2)
+		var packed uint32
+		for _, p := range parcels {
+			require.NotNil(t, p.Box)
+			assert.True(t, p.Weight <= p.Box.MaxWeight)
+			packed += p.Contents[0].Quantity
+		}
+		assert.Equal(t, uint32(10), packed)
+	})
+
+	t.Run("ships what fits no box on its own", func(*testing.T) {
+		parcels := pk.Pack([]pricing.Item{{Product: poster, Quantity: 1}, {Product: tShirt, Quantity: 1}})
+		require.Len(t, parcels, 2)
+		assert.Nil(t, parcels[0].Box)
+		assert.Equal(t, 36.0, parcels[0].Length, "product dimensions should be used without package ones")
+		assert.InDelta(t, 0.4, parcels[0].Weight, 0.001)
+		assert.Equal(t, "small", parcels[1].Box.Name)
+	})
+}
+
+func TestRateTable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shipping")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "rates.json")
+	require.Nil(t, ioutil.WriteFile(path, []byte(`
+		{
+			"zones": {
+				"domestic": [
+					{"max_weight": 10, "rate": 12.5},
+					{"max_weight": 1, "rate": 4.5},
+					{"max_weight": 5, "rate": 8}
+				]
+			}
+		}
+	`), 0644))
+
+	rt, err := shipping.LoadRateTable(path)
+	require.Nil(t, err)
+
+	t.Run("picks the lightest covering tier", func(*testing.T) {
+		rate, err := rt.Rate("domestic", 3.2)
+		assert.Nil(t, err)
+		assert.Equal(t, 8.0, rate)
+	})
+
+	t.Run("with unknown zone", func(*testing.T) {
+		_, err := rt.Rate("moon", 1)
+		assert.Equal(t, shipping.ErrUnknownZone, errors.Cause(err))
+	})
+
+	t.Run("with overweight parcel", func(*testing.T) {
+		_, err := rt.Rate("domestic", 11)
+		assert.Equal(t, shipping.ErrOverweight, errors.Cause(err))
+	})
+
+	t.Run("quote", func(*testing.T) {
+		pk := shipping.Packer{Boxes: boxes, Divisor: shipping.DivisorInchesPounds}
+		q, err := pk.Quote([]pricing.Item{{Product: tShirt, Quantity: 3}, {Product: poster, Quantity: 1}}, "domestic", rt)
+		require.Nil(t, err)
+		require.Len(t, q.Parcels, 2)
+		assert.Equal(t, 16.0, q.Total, "a poster at 2.33 billable and a box of shirts at 3.45 billable both cost 8")
+	})
+
+	t.Run("with missing file", func(*testing.T) {
+		_, err := shipping.LoadRateTable(filepath.Join(dir, "nope.json"))
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,261 @@
+// Package snapshot dumps a Dairycart store's catalog and users to a versioned JSON archive, and restores such an
+// archive into an empty store through the client's Create methods. It is meant for disaster recovery and for
+// seeding test stores with reproducible data.
+package snapshot
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dairycart/dairyclient/v1/internal/atomicfile"
+	"github.com/dairycart/dairyclient/v1/replicate"
+	"github.com/dairycart/dairymodels/v1"
+
+	"github.com/pkg/errors"
+)
+
+// FormatVersion is the archive format this package writes. Archives with any other version are refused.
+const FormatVersion = 1
+
+var (
+	// ErrStoreNotEmpty is returned when restoring into a store that already has a catalog
+	ErrStoreNotEmpty = errors.New("snapshots can only be restored into an empty store")
+	// ErrUserExists is returned when restoring an archive with a user whose username or email the target store
+	// already has
+	ErrUserExists = errors.New("snapshot user already exists in the target store")
+)
+
+// Archive is a point-in-time copy of a store. Product roots carry their options, option values and products.
+// Users never carry their password or salt.
+type Archive struct {
+	Version      int                  `json:"version"`
+	TakenOn      time.Time            `json:"taken_on"`
+	ProductRoots []models.ProductRoot `json:"product_roots"`
+	Discounts    []models.Discount    `json:"discounts"`
+	Users        []models.User        `json:"users"`
+}
+
+// Source is the subset of the client a snapshot is taken from
+type Source interface {
+	replicate.Source
+	GetAllUsers() ([]models.User, error)
+}
+
+// Target is the subset of the client a snapshot is restored into
+type Target interface {
+	replicate.Target
+	GetProductRoots(queryFilter map[string]string) ([]models.ProductRoot, error)
+	GetDiscounts(queryFilter map[string]string) ([]models.Discount, error)
+	GetAllUsers() ([]models.User, error)
+	CreateUser(nu models.UserCreationInput) (*models.User, error)
+}
+
+// Take reads the complete catalog and user list from a store
+func Take(src Source) (*Archive, error) {
+	a := &Archive{Version: FormatVersion, TakenOn: time.Now().UTC()}
+
+	roots, err := src.GetAllProductRoots()
+	if err != nil {
+		return nil, errors.Wrap(err, "encountered error listing product roots")
+	}
+	for _, listed := range roots {
+		root, err := src.GetProductRoot(listed.ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "encountered error reading product root %d", listed.ID)
+		}
+		a.ProductRoots = append(a.ProductRoots, *root)
+	}
+
+	if a.Discounts, err = src.GetAllDiscounts(); err != nil {
+		return nil, errors.Wrap(err, "encountered error listing discounts")
+	}
+
+	users, err := src.GetAllUsers()
+	if err != nil {
+		return nil, errors.Wrap(err, "encountered error listing users")
+	}
+	for _, u := range users {
+		u.Password = ""
+		u.Salt = nil
+		a.Users = append(a.Users, u)
+	}
+
+	return a, nil
+}
+
+// Write encodes the archive as JSON
+func (a *Archive) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(a)
+}
+
+// Read decodes an archive, refusing any written in a different format version
+func Read(r io.Reader) (*Archive, error) {
+	a := &Archive{}
+	if err := json.NewDecoder(r).Decode(a); err != nil {
+		return nil, errors.Wrap(err, "encountered error decoding snapshot")
+	}
+	if a.Version != FormatVersion {
+		return nil, errors.Errorf("snapshot is format version %d, but only version %d is supported", a.Version, FormatVersion)
+	}
+	return a, nil
+}
+
+// Save writes the archive to a file using internal/atomicfile
+func (a *Archive) Save(path string) error {
+	var buf bytes.Buffer
+	if err := a.Write(&buf); err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, buf.Bytes())
+}
+
+// Load reads an archive from a file
+func Load(path string) (*Archive, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+////////////////////////////////////////////////////////
+//                                                    //
+//                      Restore                       //
+//                                                    //
+////////////////////////////////////////////////////////
+
+// Result reports how the IDs in an archive map to the IDs the restored store assigned
+type Result struct {
+	Catalog *replicate.Mapping
+	Users   replicate.IDMap
+}
+
+// Restore recreates an archive's catalog and users in a store with no catalog. The store will already have at
+// least the user restoring into it, so its users are only checked against the archive's, and Restore fails with
+// ErrUserExists before creating anything if any username or email is taken. Since archives hold no passwords,
+// restored users are given a random password and will need to reset it before logging in.
+func Restore(dst Target, a *Archive) (*Result, error) {
+	roots, err := dst.GetProductRoots(map[string]string{"limit": "1"})
+	if err != nil {
+		return nil, errors.Wrap(err, "encountered error checking target product roots")
+	}
+	discounts, err := dst.GetDiscounts(map[string]string{"limit": "1"})
+	if err != nil {
+		return nil, errors.Wrap(err, "encountered error checking target discounts")
+	}
+	if len(roots) > 0 || len(discounts) > 0 {
+		return nil, ErrStoreNotEmpty
+	}
+	if err := checkUsers(dst, a.Users); err != nil {
+		return nil, err
+	}
+
+	r, err := replicate.New(a, dst, "")
+	if err != nil {
+		return nil, err
+	}
+	res := &Result{Catalog: r.Mapping(), Users: replicate.IDMap{}}
+	if err := r.Run(); err != nil {
+		return res, err
+	}
+
+	for _, u := range a.Users {
+		password, err := randomPassword()
+		if err != nil {
+			return res, err
+		}
+
+		created, err := dst.CreateUser(models.UserCreationInput{
+			FirstName: u.FirstName,
+			LastName:  u.LastName,
+			Username:  u.Username,
+			Email:     u.Email,
+			Password:  password,
+			IsAdmin:   u.IsAdmin,
+		})
+		if err != nil {
+			return res, errors.Wrapf(err, "encountered error creating user %d", u.ID)
+		}
+		res.Users[u.ID] = created.ID
+	}
+
+	return res, nil
+}
+
+// checkUsers makes sure none of users would clash with a user the target store already has
+func checkUsers(dst Target, users []models.User) error {
+	existing, err := dst.GetAllUsers()
+	if err != nil {
+		return errors.Wrap(err, "encountered error checking target users")
+	}
+
+	usernames, emails := map[string]bool{}, map[string]bool{}
+	for _, u := range existing {
+		usernames[u.Username] = true
+		emails[strings.ToLower(u.Email)] = true
+	}
+	for _, u := range users {
+		if (u.Username != "" && usernames[u.Username]) || (u.Email != "" && emails[strings.ToLower(u.Email)]) {
+			return errors.Wrapf(ErrUserExists, "user %d (%q)", u.ID, u.Username)
+		}
+	}
+	return nil
+}
+
+func randomPassword() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "encountered error generating password")
+	}
+	return hex.EncodeToString(b), nil
+}
+
+////////////////////////////////////////////////////////
+//                                                    //
+//          Reading an Archive as a Source            //
+//                                                    //
+////////////////////////////////////////////////////////
+
+// GetAllProductRoots lists the archive's product roots
+func (a *Archive) GetAllProductRoots() ([]models.ProductRoot, error) {
+	return a.ProductRoots, nil
+}
+
+// GetProductRoot returns a product root from the archive
+func (a *Archive) GetProductRoot(rootID uint64) (*models.ProductRoot, error) {
+	for _, r := range a.ProductRoots {
+		if r.ID == rootID {
+			return &r, nil
+		}
+	}
+	return nil, errors.Errorf("snapshot has no product root %d", rootID)
+}
+
+// GetAllDiscounts lists the archive's discounts
+func (a *Archive) GetAllDiscounts() ([]models.Discount, error) {
+	return a.Discounts, nil
+}
+
+// GetDiscountByID returns a discount from the archive
+func (a *Archive) GetDiscountByID(discountID uint64) (*models.Discount, error) {
+	for _, d := range a.Discounts {
+		if d.ID == discountID {
+			return &d, nil
+		}
+	}
+	return nil, errors.Errorf("snapshot has no discount %d", discountID)
+}
+
+// GetAllUsers lists the archive's users
+func (a *Archive) GetAllUsers() ([]models.User, error) {
+	return a.Users, nil
+}
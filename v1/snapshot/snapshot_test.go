@@ -0,0 +1,268 @@
+package snapshot_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dairycart/dairyclient/v1"
+	"github.com/dairycart/dairyclient/v1/snapshot"
+	"github.com/dairycart/dairymodels/v1"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ snapshot.Source = (*dairyclient.V1Client)(nil)
+	_ snapshot.Target = (*dairyclient.V1Client)(nil)
+	_ snapshot.Source = (*snapshot.Archive)(nil)
+)
+
+const exampleUsersResponse = `
+	{
+		"count": 1,
+		"limit": 25,
+		"page": 1,
+		"users": [
+			{
+				"id": 1,
+				"first_name": "Frank",
+				"last_name": "Zappa",
+				"email": "frank@zappa.com",
+				"password": "hunter2",
+				"salt": "c2FsdA==",
+				"is_admin": true
+			}
+		]
+	}
+`
+
+func loadExampleResponse(t *testing.T, name string) string {
+	t.Helper()
+	data, err := ioutil.ReadFile(filepath.Join("..", "example_responses", name+".json"))
+	require.Nil(t, err)
+	return string(data)
+}
+
+func buildTestClient(t *testing.T, ts *httptest.Server) *dairyclient.V1Client {
+	u, err := url.Parse(ts.URL)
+	require.Nil(t, err)
+	return &dairyclient.V1Client{
+		URL:        u,
+		Client:     ts.Client(),
+		AuthCookie: &http.Cookie{Name: "dairycart"},
+	}
+}
+
+func TestTake(t *testing.T) {
+	responses := map[string]string{
+		"/v1/product_roots": loadExampleResponse(t, "product_roots"),
+		"/v1/discounts":     loadExampleResponse(t, "discounts"),
+		"/v1/users":         exampleUsersResponse,
+	}
+	root := loadExampleResponse(t, "product_root")
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if strings.HasPrefix(req.URL.Path, "/v1/product_root/") {
+			res.Write([]byte(root))
+			return
+		}
+		if body, ok := responses[req.URL.Path]; ok {
+			// the example responses count more entities than they hold, so every later page is empty
+			if p := req.URL.Query().Get("page"); p != "" && p != "1" {
+				body = "{}"
+			}
+			res.Write([]byte(body))
+			return
+		}
+		http.NotFound(res, req)
+	}))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	a, err := snapshot.Take(c)
+	require.Nil(t, err)
+
+	assert.Equal(t, snapshot.FormatVersion, a.Version)
+	require.Len(t, a.ProductRoots, 2)
+	assert.NotEmpty(t, a.ProductRoots[0].Options, "roots should carry their options")
+	assert.NotEmpty(t, a.ProductRoots[0].Products, "roots should carry their products")
+	assert.Len(t, a.Discounts, 3)
+
+	require.Len(t, a.Users, 1)
+	assert.Equal(t, "Frank", a.Users[0].FirstName)
+	assert.Empty(t, a.Users[0].Password, "passwords should never be archived")
+	assert.Empty(t, a.Users[0].Salt, "salts should never be archived")
+}
+
+func TestArchiveReadWrite(t *testing.T) {
+	a := &snapshot.Archive{
+		Version:      snapshot.FormatVersion,
+		ProductRoots: []models.ProductRoot{{ID: 1, Name: "T-Shirt"}},
+		Discounts:    []models.Discount{{ID: 2, Name: "10 percent off"}},
+	}
+
+	t.Run("round trip", func(*testing.T) {
+		var buf bytes.Buffer
+		require.Nil(t, a.Write(&buf))
+
+		actual, err := snapshot.Read(&buf)
+		require.Nil(t, err)
+		assert.Equal(t, a.ProductRoots[0].Name, actual.ProductRoots[0].Name)
+		assert.Equal(t, a.Discounts[0].Name, actual.Discounts[0].Name)
+	})
+
+	t.Run("with file", func(*testing.T) {
+		dir, err := ioutil.TempDir("", "snapshot")
+		require.Nil(t, err)
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "snapshot.json")
+		require.Nil(t, a.Save(path))
+		actual, err := snapshot.Load(path)
+		require.Nil(t, err)
+		assert.Len(t, actual.ProductRoots, 1)
+	})
+
+	t.Run("with unsupported version", func(*testing.T) {
+		_, err := snapshot.Read(strings.NewReader(`{"version": 99}`))
+		assert.NotNil(t, err)
+	})
+
+	t.Run("with invalid JSON", func(*testing.T) {
+		_, err := snapshot.Read(strings.NewReader(`{"invalid lol}`))
+		assert.NotNil(t, err)
+	})
+}
+
+// fakeTarget records what is created in it, assigning IDs from 100 upwards
+type fakeTarget struct {
+	nextID    uint64
+	roots     []models.ProductRoot
+	discounts []models.Discount
+	existing  []models.User
+	users     []models.UserCreationInput
+}
+
+func (ft *fakeTarget) id() uint64 {
+	ft.nextID++
+	return 100 + ft.nextID
+}
+
+func (ft *fakeTarget) GetProductRoots(map[string]string) ([]models.ProductRoot, error) {
+	return ft.roots, nil
+}
+
+func (ft *fakeTarget) GetAllProductRoots() ([]models.ProductRoot, error) {
+	return ft.roots, nil
+}
+
+func (ft *fakeTarget) GetProductRoot(uint64) (*models.ProductRoot, error) {
+	return nil, nil
+}
+
+func (ft *fakeTarget) GetDiscounts(map[string]string) ([]models.Discount, error) {
+	return ft.discounts, nil
+}
+
+func (ft *fakeTarget) GetAllDiscounts() ([]models.Discount, error) {
+	return ft.discounts, nil
+}
+
+func (ft *fakeTarget) GetDiscountByID(uint64) (*models.Discount, error) {
+	return nil, nil
+}
+
+func (ft *fakeTarget) CreateProductRoot(nr dairyclient.ProductRootCreationInput) (*models.ProductRoot, error) {
+	r := models.ProductRoot{ID: ft.id(), Name: nr.Name}
+	ft.roots = append(ft.roots, r)
+	return &r, nil
+}
+
+func (ft *fakeTarget) CreateProductOption(rootID uint64, no models.ProductOptionCreationInput) (*models.ProductOption, error) {
+	o := &models.ProductOption{ID: ft.id(), Name: no.Name, ProductRootID: rootID}
+	for _, v := range no.Values {
+		o.Values = append(o.Values, models.ProductOptionValue{ID: ft.id(), Value: v})
+	}
+	return o, nil
+}
+
+func (ft *fakeTarget) CreateProductOptionValue(optionID uint64, nv models.ProductOptionValueCreationInput) (*models.ProductOptionValue, error) {
+	return &models.ProductOptionValue{ID: ft.id(), ProductOptionID: optionID, Value: nv.Value}, nil
+}
+
+func (ft *fakeTarget) CreateProductRootVariant(rootID uint64, np models.ProductCreationInput) (*models.Product, error) {
+	return &models.Product{ID: ft.id(), ProductRootID: rootID, SKU: np.SKU}, nil
+}
+
+func (ft *fakeTarget) CreateDiscount(nd models.DiscountCreationInput) (*models.Discount, error) {
+	d := models.Discount{ID: ft.id(), Name: nd.Name}
+	ft.discounts = append(ft.discounts, d)
+	return &d, nil
+}
+
+func (ft *fakeTarget) GetAllUsers() ([]models.User, error) {
+	return ft.existing, nil
+}
+
+func (ft *fakeTarget) CreateUser(nu models.UserCreationInput) (*models.User, error) {
+	ft.users = append(ft.users, nu)
+	return &models.User{ID: ft.id(), FirstName: nu.FirstName}, nil
+}
+
+func TestRestore(t *testing.T) {
+	a := &snapshot.Archive{
+		Version: snapshot.FormatVersion,
+		ProductRoots: []models.ProductRoot{
+			{
+				ID:   1,
+				Name: "T-Shirt",
+				Options: []models.ProductOption{
+					{ID: 2, Name: "color", Values: []models.ProductOptionValue{{ID: 3, Value: "red"}}},
+				},
+				Products: []models.Product{{ID: 4, ProductRootID: 1, SKU: "t-shirt-red"}},
+			},
+		},
+		Discounts: []models.Discount{{ID: 5, Name: "10 percent off"}},
+		Users:     []models.User{{ID: 6, FirstName: "Frank", Username: "frank", Email: "frank@zappa.com", IsAdmin: true}},
+	}
+
+	t.Run("normal usage", func(*testing.T) {
+		ft := &fakeTarget{existing: []models.User{{ID: 1, Username: "admin", Email: "admin@example.com"}}}
+		res, err := snapshot.Restore(ft, a)
+		require.Nil(t, err)
+
+		assert.Len(t, res.Catalog.ProductRoots, 1)
+		assert.Len(t, res.Catalog.ProductOptions, 1)
+		assert.Len(t, res.Catalog.ProductOptionValues, 1)
+		assert.Len(t, res.Catalog.Products, 1)
+		assert.Len(t, res.Catalog.Discounts, 1)
+		assert.Len(t, res.Users, 1)
+		assert.NotEqual(t, uint64(6), res.Users[6])
+
+		require.Len(t, ft.users, 1)
+		assert.True(t, ft.users[0].IsAdmin)
+		assert.NotEmpty(t, ft.users[0].Password, "restored users should get a generated password")
+	})
+
+	t.Run("into a store that isn't empty", func(*testing.T) {
+		ft := &fakeTarget{roots: []models.ProductRoot{{ID: 1}}}
+		_, err := snapshot.Restore(ft, a)
+		assert.Equal(t, snapshot.ErrStoreNotEmpty, err)
+	})
+
+	t.Run("with a user the store already has", func(*testing.T) {
+		ft := &fakeTarget{existing: []models.User{{ID: 1, Username: "zappa", Email: "Frank@Zappa.com"}}}
+		_, err := snapshot.Restore(ft, a)
+		assert.Equal(t, snapshot.ErrUserExists, errors.Cause(err))
+		assert.Empty(t, ft.roots, "nothing should be created when a user would clash")
+	})
+}
@@ -0,0 +1,99 @@
+package dairyclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/dairycart/dairymodels/v1"
+
+	"github.com/pkg/errors"
+)
+
+// streamList requests a list endpoint and decodes the array stored under key one element at a time, calling fn
+// with each element instead of holding the whole page in memory. Errors returned by fn stop the stream and are
+// returned as-is; every other error is a *ClientError.
+func streamList[T any](ctx context.Context, dc *V1Client, uri string, key string, fn func(T) error) error {
+	res, err := dc.send(ctx, http.MethodGet, uri, nil, nil)
+	if err != nil {
+		return err
+	}
+	if isErrorStatus(res.StatusCode) {
+		_, ce := unmarshalBody[json.RawMessage](res)
+		return ce
+	}
+	defer res.Body.Close()
+
+	dec := json.NewDecoder(res.Body)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return &ClientError{Err: errors.Wrap(err, "encountered error reading response from server")}
+		}
+
+		if tok != key {
+			var skipped json.RawMessage
+			if err := dec.Decode(&skipped); err != nil {
+				return &ClientError{Err: errors.Wrap(err, "encountered error reading response from server")}
+			}
+			continue
+		}
+
+		if err := expectDelim(dec, '['); err != nil {
+			return err
+		}
+		for dec.More() {
+			item := new(T)
+			if err := dec.Decode(item); err != nil {
+				return &ClientError{Err: errors.Wrap(err, "encountered error loading response from server")}
+			}
+			if err := fn(*item); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return &ClientError{Err: errors.Wrap(err, "encountered error reading response from server")}
+	}
+	if tok != want {
+		return &ClientError{Err: errors.Errorf("expected %q in response from server, got %v", want, tok)}
+	}
+	return nil
+}
+
+// StreamProducts calls fn with each product in a page of the product list as it is decoded
+func (dc *V1Client) StreamProducts(queryFilter map[string]string, fn func(models.Product) error) error {
+	u := dc.buildURL(queryFilter, "products")
+	return streamList(context.Background(), dc, u, "products", fn)
+}
+
+// StreamProductRoots calls fn with each product root in a page of the product root list as it is decoded
+func (dc *V1Client) StreamProductRoots(queryFilter map[string]string, fn func(models.ProductRoot) error) error {
+	u := dc.buildURL(queryFilter, "product_roots")
+	return streamList(context.Background(), dc, u, "product_roots", fn)
+}
+
+// StreamDiscounts calls fn with each discount in a page of the discount list as it is decoded
+func (dc *V1Client) StreamDiscounts(queryFilter map[string]string, fn func(models.Discount) error) error {
+	u := dc.buildURL(queryFilter, "discounts")
+	return streamList(context.Background(), dc, u, "discounts", fn)
+}
+
+// StreamOrders calls fn with each order in a page of the order list as it is decoded
+func (dc *V1Client) StreamOrders(filter OrderFilter, fn func(Order) error) error {
+	u := dc.buildURL(filter.queryFilter(), "orders")
+	return streamList(context.Background(), dc, u, "orders", fn)
+}
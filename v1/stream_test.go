@@ -0,0 +1,147 @@
+package dairyclient_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dairycart/dairyclient/v1"
+	"github.com/dairycart/dairymodels/v1"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamProducts(t *testing.T) {
+	exampleGoodResponse := loadExampleResponse(t, "products")
+
+	t.Run("normal usage", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/products": generateGetHandler(t, exampleGoodResponse, http.StatusOK),
+		}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		expected, err := c.GetProducts(nil)
+		require.Nil(t, err)
+
+		var actual []models.Product
+		err = c.StreamProducts(nil, func(p models.Product) error {
+			actual = append(actual, p)
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual, "streamed products should match the decoded list")
+	})
+
+	t.Run("stops when the callback fails", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/products": generateGetHandler(t, exampleGoodResponse, http.StatusOK),
+		}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		stop := errors.New("that's enough")
+		var seen int
+		err := c.StreamProducts(nil, func(models.Product) error {
+			seen++
+			return stop
+		})
+		assert.Equal(t, stop, err, "callback errors should be returned as-is")
+		assert.Equal(t, 1, seen)
+	})
+
+	t.Run("with error response", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/products": generateGetHandler(t, `{"status":500,"message":"obligatory error"}`, http.StatusInternalServerError),
+		}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		err := c.StreamProducts(nil, func(models.Product) error { return nil })
+		require.NotNil(t, err)
+		ce, ok := err.(*dairyclient.ClientError)
+		require.True(t, ok)
+		assert.NotNil(t, ce.FromAPI)
+	})
+
+	t.Run("with invalid response", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/products": generateGetHandler(t, `{"products": [{"id": "not a number"}]}`, http.StatusOK),
+		}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		err := c.StreamProducts(nil, func(models.Product) error { return nil })
+		assert.NotNil(t, err)
+	})
+}
+
+func TestStreamDiscounts(t *testing.T) {
+	exampleGoodResponse := loadExampleResponse(t, "discounts")
+
+	handlers := map[string]http.HandlerFunc{
+		"/v1/discounts": generateGetHandler(t, exampleGoodResponse, http.StatusOK),
+	}
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	expected, err := c.GetDiscounts(nil)
+	require.Nil(t, err)
+
+	var actual []models.Discount
+	err = c.StreamDiscounts(nil, func(d models.Discount) error {
+		actual = append(actual, d)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual, "streamed discounts should match the decoded list")
+}
+
+func TestMaxResponseSize(t *testing.T) {
+	big := fmt.Sprintf(`{"products": [], "padding": "%s"}`, strings.Repeat("a", 1024))
+
+	handlers := map[string]http.HandlerFunc{
+		"/v1/products": func(res http.ResponseWriter, req *http.Request) {
+			// flushing first forces a chunked response with no Content-Length
+			res.(http.Flusher).Flush()
+			fmt.Fprint(res, big)
+		},
+	}
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("under the limit", func(*testing.T) {
+		c.MaxResponseSize = 4096
+		_, err := c.GetProducts(nil)
+		assert.Nil(t, err)
+	})
+
+	t.Run("over the limit", func(*testing.T) {
+		c.MaxResponseSize = 512
+		_, err := c.GetProducts(nil)
+		require.NotNil(t, err)
+		ce, ok := err.(*dairyclient.ClientError)
+		require.True(t, ok)
+		assert.Equal(t, dairyclient.ErrResponseTooLarge, pkgerrors.Cause(ce.Err))
+	})
+
+	t.Run("while streaming", func(*testing.T) {
+		c.MaxResponseSize = 512
+		err := c.StreamProducts(nil, func(models.Product) error { return nil })
+		require.NotNil(t, err)
+		ce, ok := err.(*dairyclient.ClientError)
+		require.True(t, ok)
+		assert.Equal(t, dairyclient.ErrResponseTooLarge, pkgerrors.Cause(ce.Err))
+	})
+}
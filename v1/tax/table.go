@@ -0,0 +1,85 @@
+package tax
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultCategory is the category of products the table doesn't categorize, and the rate used for categories a
+// region doesn't list
+const DefaultCategory = "default"
+
+// ErrUnknownRegion is returned when a table has no rates for a region
+var ErrUnknownRegion = errors.New("no tax rates for region")
+
+// Table is a Calculator that looks rates up by region and product category
+type Table struct {
+	// Rates maps a region to the percentage rate for each category in it
+	Rates map[string]map[string]float64 `json:"rates"`
+	// Categories maps a SKU, or a SKU prefix such as a product root's, to a category. The longest match wins.
+	Categories map[string]string `json:"categories"`
+}
+
+// LoadTable reads a Table from a JSON file shaped like
+//
+//	{
+//		"rates": {"TX": {"default": 8.25, "clothing": 0}},
+//		"categories": {"t-shirt": "clothing"}
+//	}
+func LoadTable(path string) (*Table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	t := &Table{}
+	if err := json.NewDecoder(f).Decode(t); err != nil {
+		return nil, errors.Wrap(err, "encountered error decoding tax table")
+	}
+	return t, nil
+}
+
+// Category returns the category the table puts a SKU in
+func (t *Table) Category(sku string) string {
+	if c, ok := t.Categories[sku]; ok {
+		return c
+	}
+
+	category, longest := DefaultCategory, 0
+	for prefix, c := range t.Categories {
+		if len(prefix) > longest && strings.HasPrefix(sku, prefix) {
+			category, longest = c, len(prefix)
+		}
+	}
+	return category
+}
+
+// Calculate taxes each taxable line at its category's rate in region. Untaxable lines are listed with no tax.
+func (t *Table) Calculate(region string, lines []Line) (*Breakdown, error) {
+	rates, ok := t.Rates[region]
+	if !ok {
+		return nil, errors.Wrapf(ErrUnknownRegion, "region %q", region)
+	}
+
+	b := &Breakdown{Region: region}
+	for _, l := range lines {
+		lt := LineTax{SKU: l.SKU, Amount: l.Amount}
+		if l.Taxable {
+			lt.Category = t.Category(l.SKU)
+			rate, ok := rates[lt.Category]
+			if !ok {
+				rate = rates[DefaultCategory]
+			}
+			lt.Rate = rate
+			lt.Tax = roundToCents(l.Amount * rate / 100)
+		}
+		b.Lines = append(b.Lines, lt)
+		b.Tax += lt.Tax
+	}
+	b.Tax = roundToCents(b.Tax)
+	return b, nil
+}
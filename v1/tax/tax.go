@@ -0,0 +1,81 @@
+// Package tax computes sales tax on taxable products. Calculator is the extension point; Table is a built-in
+// Calculator driven by a local file of rates by region and product category. CartTotal ties a Calculator into
+// the pricing package's cart totals.
+package tax
+
+import (
+	"math"
+
+	"github.com/dairycart/dairyclient/v1/pricing"
+	"github.com/dairycart/dairymodels/v1"
+)
+
+// Line is an amount to be taxed for one SKU. Amount should already have any discounts taken off.
+type Line struct {
+	SKU     string
+	Taxable bool
+	Amount  float64
+}
+
+// LineTax is the tax charged on one Line. Rate is a percentage, so 8.25 means 8.25%.
+type LineTax struct {
+	SKU      string
+	Category string
+	Amount   float64
+	Rate     float64
+	Tax      float64
+}
+
+// Breakdown is the tax charged on a set of Lines, line by line
+type Breakdown struct {
+	Region string
+	Lines  []LineTax
+	Tax    float64
+}
+
+// Calculator computes the tax on a set of lines for a region. CartTotal passes lines that are already discounted,
+// so an implementation only needs to know rates, not how the cart was priced.
+type Calculator interface {
+	Calculate(region string, lines []Line) (*Breakdown, error)
+}
+
+func roundToCents(f float64) float64 {
+	return math.Round(f*100) / 100
+}
+
+// LinesFromTotals converts priced cart lines into taxable lines, spreading the cart's discount across them in
+// proportion to each line's total. Any rounding remainder goes to the last line, so the lines always add up to
+// the discounted subtotal.
+func LinesFromTotals(t pricing.Totals) []Line {
+	lines := make([]Line, 0, len(t.Lines))
+	remaining := t.Discount
+	for i, l := range t.Lines {
+		share := remaining
+		if i < len(t.Lines)-1 {
+			share = 0
+			if t.Subtotal > 0 {
+				share = roundToCents(t.Discount * l.Total / t.Subtotal)
+			}
+			remaining = roundToCents(remaining - share)
+		}
+		lines = append(lines, Line{SKU: l.SKU, Taxable: l.Taxable, Amount: roundToCents(l.Total - share)})
+	}
+	return lines
+}
+
+// Totals is pricing's cart total with tax added
+type Totals struct {
+	pricing.Totals
+	Tax        *Breakdown
+	GrandTotal float64
+}
+
+// CartTotal prices a cart as pricing.CartTotal does, then taxes the discounted lines for a region
+func CartTotal(items []pricing.Item, discounts []models.Discount, s pricing.Shopper, c Calculator, region string) (*Totals, error) {
+	t := pricing.CartTotal(items, discounts, s)
+	b, err := c.Calculate(region, LinesFromTotals(t))
+	if err != nil {
+		return nil, err
+	}
+	return &Totals{Totals: t, Tax: b, GrandTotal: roundToCents(t.Total + b.Tax)}, nil
+}
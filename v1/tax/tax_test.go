@@ -0,0 +1,142 @@
+package tax_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dairycart/dairyclient/v1/pricing"
+	"github.com/dairycart/dairyclient/v1/tax"
+	"github.com/dairycart/dairymodels/v1"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ tax.Calculator = (*tax.Table)(nil)
+
+func buildTestTable(t *testing.T) *tax.Table {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "tax")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "rates.json")
+	require.Nil(t, ioutil.WriteFile(path, []byte(`
+		{
+			"rates": {
+				"TX": {"default": 8.25, "clothing": 2},
+				"OR": {}
+			},
+			"categories": {
+				"t-shirt": "clothing",
+				"t-shirt-special": "default"
+			}
+		}
+	`), 0644))
+
+	table, err := tax.LoadTable(path)
+	require.Nil(t, err)
+	return table
+}
+
+func TestTableCategory(t *testing.T) {
+	table := buildTestTable(t)
+	assert.Equal(t, "clothing", table.Category("t-shirt"))
+	assert.Equal(t, "clothing", table.Category("t-shirt-small-red"))
+	assert.Equal(t, tax.DefaultCategory, table.Category("t-shirt-special-edition"), "the longest prefix should win")
+	assert.Equal(t, tax.DefaultCategory, table.Category("mug"))
+}
+
+func TestTableCalculate(t *testing.T) {
+	table := buildTestTable(t)
+	lines := []tax.Line{
+		{SKU: "t-shirt-small-red", Taxable: true, Amount: 40},
+		{SKU: "mug", Taxable: true, Amount: 9},
+		{SKU: "gift-card", Taxable: false, Amount: 25},
+	}
+
+	t.Run("normal usage", func(*testing.T) {
+		b, err := table.Calculate("TX", lines)
+		require.Nil(t, err)
+
+		expected := []tax.LineTax{
+			{SKU: "t-shirt-small-red", Category: "clothing", Amount: 40, Rate: 2, Tax: 0.8},
+			{SKU: "mug", Category: tax.DefaultCategory, Amount: 9, Rate: 8.25, Tax: 0.74},
+			{SKU: "gift-card", Amount: 25},
+		}
+		assert.Equal(t, expected, b.Lines)
+		assert.Equal(t, 1.54, b.Tax)
+	})
+
+	t.Run("with region without rates", func(*testing.T) {
+		b, err := table.Calculate("OR", lines)
+		require.Nil(t, err)
+		assert.Equal(t, 0.0, b.Tax)
+	})
+
+	t.Run("with unknown region", func(*testing.T) {
+		_, err := table.Calculate("XX", lines)
+		assert.Equal(t, tax.ErrUnknownRegion, errors.Cause(err))
+	})
+
+	t.Run("with missing file", func(*testing.T) {
+		_, err := tax.LoadTable("nonexistent.json")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestCartTotal(t *testing.T) {
+	at := time.Date(2017, 12, 10, 15, 58, 43, 0, time.UTC)
+	items := []pricing.Item{
+		{Product: models.Product{SKU: "t-shirt-small-red", Price: 20, Taxable: true}, Quantity: 2},
+		{Product: models.Product{SKU: "mug", Price: 10, Taxable: true}, Quantity: 1},
+		{Product: models.Product{SKU: "gift-card", Price: 25}, Quantity: 1},
+	}
+	discounts := []models.Discount{
+		{ID: 1, DiscountType: pricing.PercentageDiscount, Amount: 10, StartsOn: at.Add(-time.Hour)},
+	}
+
+	t.Run("spreads the discount before taxing", func(*testing.T) {
+		totals, err := tax.CartTotal(items, discounts, pricing.Shopper{At: at}, buildTestTable(t), "TX")
+		require.Nil(t, err)
+
+		assert.Equal(t, 67.5, totals.Total)
+		require.Len(t, totals.Tax.Lines, 3)
+		assert.Equal(t, 36.0, totals.Tax.Lines[0].Amount)
+		assert.Equal(t, 9.0, totals.Tax.Lines[1].Amount)
+		assert.Equal(t, 22.5, totals.Tax.Lines[2].Amount)
+		assert.Equal(t, 1.46, totals.Tax.Tax)
+		assert.Equal(t, 68.96, totals.GrandTotal)
+	})
+
+	t.Run("with calculator error", func(*testing.T) {
+		_, err := tax.CartTotal(items, discounts, pricing.Shopper{At: at}, buildTestTable(t), "XX")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestLinesFromTotals(t *testing.T) {
+	totals := pricing.Totals{
+		Lines: []pricing.Line{
+			{SKU: "a", Total: 10},
+			{SKU: "b", Total: 10},
+			{SKU: "c", Total: 10},
+		},
+		Subtotal: 30,
+		Discount: 10,
+	}
+
+	lines := tax.LinesFromTotals(totals)
+	require.Len(t, lines, 3)
+	var sum float64
+	for _, l := range lines {
+		sum += l.Amount
+	}
+	assert.InDelta(t, 20.0, sum, 0.001, "rounding remainders should land on the last line")
+	assert.Equal(t, 6.67, lines[0].Amount)
+	assert.Equal(t, 6.66, lines[2].Amount)
+}
@@ -1,35 +1,120 @@
 package dairyclient
 
 import (
-	"net/http"
-
 	"github.com/dairycart/dairymodels/v1"
 )
 
+// PasswordUpdateInput is the body sent when a user changes their own password
+type PasswordUpdateInput struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// PasswordResetRequestInput is the body sent when a user asks for a password reset
+type PasswordResetRequestInput struct {
+	Username string `json:"username"`
+}
+
+// PasswordResetConfirmationInput is the body sent when a user redeems a password reset token
+type PasswordResetConfirmationInput struct {
+	NewPassword string `json:"new_password"`
+}
+
+// AdminFlagInput is the body sent when granting or revoking a user's admin privileges
+type AdminFlagInput struct {
+	IsAdmin bool `json:"is_admin"`
+}
+
 ////////////////////////////////////////////////////////
 //                                                    //
 //                  User Functions                    //
 //                                                    //
 ////////////////////////////////////////////////////////
 
+// GetUser retrieves a user with a given ID
+func (dc *V1Client) GetUser(userID uint64) (*models.User, error) {
+	userIDString := convertIDToString(userID)
+	u := dc.buildURL(nil, "user", userIDString)
+	return get[models.User](dc, u)
+}
+
+// GetUsers retrieves a page of users. Pagination is controlled by the `page` and `limit` query filters
+func (dc *V1Client) GetUsers(queryFilter map[string]string) ([]models.User, error) {
+	u := dc.buildURL(queryFilter, "users")
+	ul, err := get[models.UserListResponse](dc, u)
+	if err != nil {
+		return nil, err
+	}
+
+	return ul.Users, nil
+}
+
+// GetAllUsers pages through the user list until every user has been retrieved
+func (dc *V1Client) GetAllUsers() ([]models.User, error) {
+	return getAll(func(page uint64) ([]models.User, uint64, error) {
+		u := dc.buildURL(map[string]string{"page": convertIDToString(page)}, "users")
+		ul, err := get[models.UserListResponse](dc, u)
+		if err != nil {
+			return nil, 0, err
+		}
+		return ul.Users, uint64(ul.Count), nil
+	})
+}
+
 // CreateUser takes a UserCreationInput and creates the user in Dairycart
 func (dc *V1Client) CreateUser(nu models.UserCreationInput) (*models.User, error) {
+	if err := dc.validate(nu); err != nil {
+		return nil, err
+	}
 	u := dc.buildURL(nil, "user")
-	body, _ := createBodyFromStruct(nu)
+	return post[models.User](dc, u, nu)
+}
 
-	req, _ := http.NewRequest(http.MethodPost, u, body)
-	res, err := dc.executeRequest(req)
-	if err != nil {
-		return nil, err
+// UpdateUser takes a UserUpdateInput and applies it to the user with a given ID
+func (dc *V1Client) UpdateUser(userID uint64, uu models.UserUpdateInput) (*models.User, error) {
+	userIDString := convertIDToString(userID)
+	u := dc.buildURL(nil, "user", userIDString)
+	return patch[models.User](dc, u, uu)
+}
+
+// UpdatePassword changes a user's password, provided the current password is correct
+func (dc *V1Client) UpdatePassword(userID uint64, currentPassword string, newPassword string) (*models.User, error) {
+	userIDString := convertIDToString(userID)
+	u := dc.buildURL(nil, "user", userIDString, "password")
+
+	in := PasswordUpdateInput{
+		CurrentPassword: currentPassword,
+		NewPassword:     newPassword,
 	}
+	return patch[models.User](dc, u, in)
+}
 
-	ru := models.User{}
-	apiErr := unmarshalBody(res, &ru)
-	if apiErr != nil {
-		return nil, apiErr
+// RequestPasswordReset asks Dairycart to issue a password reset token for a given username
+func (dc *V1Client) RequestPasswordReset(username string) error {
+	u := dc.buildURL(nil, "password_reset")
+	in := PasswordResetRequestInput{Username: username}
+
+	_, err := post[models.ErrorResponse](dc, u, in)
+	return err
+}
+
+// ConfirmPasswordReset redeems a password reset token, setting the user's password to newPassword
+func (dc *V1Client) ConfirmPasswordReset(resetToken string, newPassword string) error {
+	if err := requireIdentifier("reset token", resetToken); err != nil {
+		return err
 	}
+	u := dc.buildURL(nil, "password_reset", resetToken)
+	in := PasswordResetConfirmationInput{NewPassword: newPassword}
+
+	_, err := post[models.ErrorResponse](dc, u, in)
+	return err
+}
 
-	return &ru, nil
+// SetUserAdminStatus grants or revokes admin privileges for the user with a given ID
+func (dc *V1Client) SetUserAdminStatus(userID uint64, isAdmin bool) (*models.User, error) {
+	userIDString := convertIDToString(userID)
+	u := dc.buildURL(nil, "user", userIDString, "admin")
+	return patch[models.User](dc, u, AdminFlagInput{IsAdmin: isAdmin})
 }
 
 // DeleteUser deletes a user with a given ID
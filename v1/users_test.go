@@ -21,7 +21,9 @@ func TestCreateUser(t *testing.T) {
 	exampleInput := models.UserCreationInput{
 		FirstName: "First",
 		LastName:  "Last",
+		Username:  "username",
 		Email:     "email@address.com",
+		Password:  "password",
 	}
 
 	t.Run("normal usage", func(*testing.T) {
@@ -29,7 +31,9 @@ func TestCreateUser(t *testing.T) {
 			{
 				"first_name": "First",
 				"last_name": "Last",
-				"email": "email@address.com"
+				"username": "username",
+				"email": "email@address.com",
+				"password": "password"
 			}
 		`
 		responseBody := `
@@ -78,7 +82,9 @@ func TestCreateUser(t *testing.T) {
 			{
 				"first_name": "First",
 				"last_name": "Last",
-				"email": "email@address.com"
+				"username": "username",
+				"email": "email@address.com",
+				"password": "password"
 			}
 		`
 		badResponse := `
@@ -154,3 +160,250 @@ func TestDeleteUser(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 }
+
+func TestGetUser(t *testing.T) {
+	existentID, nonexistentID := uint64(1), uint64(2)
+	exampleResponseJSON := loadExampleResponse(t, "user")
+
+	handlers := map[string]http.HandlerFunc{
+		fmt.Sprintf("/v1/user/%d", existentID):    generateGetHandler(t, exampleResponseJSON, http.StatusOK),
+		fmt.Sprintf("/v1/user/%d", nonexistentID): generateGetHandler(t, buildNotFoundUserResponse(nonexistentID), http.StatusNotFound),
+	}
+
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("normal usage", func(*testing.T) {
+		expected := &models.User{
+			ID:        1,
+			FirstName: "Frank",
+			LastName:  "Zappa",
+			Email:     "frank@zappa.com",
+			CreatedOn: buildTestTime(t),
+		}
+
+		actual, err := c.GetUser(existentID)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("with nonexistent user", func(*testing.T) {
+		_, err := c.GetUser(nonexistentID)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestGetUsers(t *testing.T) {
+	t.Run("normal usage", func(*testing.T) {
+		exampleResponseJSON := loadExampleResponse(t, "users")
+		handlers := map[string]http.HandlerFunc{
+			"/v1/users": generateGetHandler(t, exampleResponseJSON, http.StatusOK),
+		}
+
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		expected := []models.User{
+			{
+				ID:        1,
+				FirstName: "Frank",
+				LastName:  "Zappa",
+				Email:     "frank@zappa.com",
+				CreatedOn: buildTestTime(t),
+			},
+			{
+				ID:        2,
+				FirstName: "Captain",
+				LastName:  "Beefheart",
+				Email:     "captain@beefheart.com",
+				IsAdmin:   true,
+				CreatedOn: buildTestTime(t),
+			},
+		}
+
+		actual, err := c.GetUsers(map[string]string{"page": "1"})
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("with bad server response", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/users": generateGetHandler(t, exampleBadJSON, http.StatusOK),
+		}
+
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		_, err := c.GetUsers(nil)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestUpdateUser(t *testing.T) {
+	existentID, nonexistentID := uint64(1), uint64(2)
+	exampleResponseJSON := loadExampleResponse(t, "updated_user")
+	expectedBody := `
+		{
+			"first_name": "Frank"
+		}
+	`
+	exampleInput := models.UserUpdateInput{
+		FirstName: "Frank",
+	}
+
+	handlers := map[string]http.HandlerFunc{
+		fmt.Sprintf("/v1/user/%d", existentID):    generatePatchHandler(t, expectedBody, exampleResponseJSON, http.StatusOK),
+		fmt.Sprintf("/v1/user/%d", nonexistentID): generatePatchHandler(t, expectedBody, buildNotFoundUserResponse(nonexistentID), http.StatusNotFound),
+	}
+
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("normal usage", func(*testing.T) {
+		expected := &models.User{
+			ID:        1,
+			FirstName: "Frank",
+			LastName:  "Zappa",
+			Email:     "frank@zappa.com",
+			IsAdmin:   true,
+			CreatedOn: buildTestTime(t),
+			UpdatedOn: buildTestDairytime(t),
+		}
+
+		actual, err := c.UpdateUser(existentID, exampleInput)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("with nonexistent user", func(*testing.T) {
+		_, err := c.UpdateUser(nonexistentID, exampleInput)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestUpdatePassword(t *testing.T) {
+	existentID, nonexistentID := uint64(1), uint64(2)
+	exampleResponseJSON := loadExampleResponse(t, "updated_user")
+	expectedBody := `
+		{
+			"current_password": "old",
+			"new_password": "new"
+		}
+	`
+
+	handlers := map[string]http.HandlerFunc{
+		fmt.Sprintf("/v1/user/%d/password", existentID):    generatePatchHandler(t, expectedBody, exampleResponseJSON, http.StatusOK),
+		fmt.Sprintf("/v1/user/%d/password", nonexistentID): generatePatchHandler(t, expectedBody, buildNotFoundUserResponse(nonexistentID), http.StatusNotFound),
+	}
+
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("normal usage", func(*testing.T) {
+		actual, err := c.UpdatePassword(existentID, "old", "new")
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1), actual.ID)
+	})
+
+	t.Run("with nonexistent user", func(*testing.T) {
+		_, err := c.UpdatePassword(nonexistentID, "old", "new")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestRequestPasswordReset(t *testing.T) {
+	expectedBody := `
+		{
+			"username": "frankzappa"
+		}
+	`
+
+	t.Run("normal usage", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/password_reset": generatePostHandler(t, expectedBody, "{}", http.StatusAccepted),
+		}
+
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		err := c.RequestPasswordReset("frankzappa")
+		assert.Nil(t, err)
+	})
+
+	t.Run("with error response", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/password_reset": generatePostHandler(t, expectedBody, `{"status":404,"message":"user not found"}`, http.StatusNotFound),
+		}
+
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		err := c.RequestPasswordReset("frankzappa")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestConfirmPasswordReset(t *testing.T) {
+	goodToken, badToken := "good", "bad"
+	expectedBody := `
+		{
+			"new_password": "new"
+		}
+	`
+
+	handlers := map[string]http.HandlerFunc{
+		fmt.Sprintf("/v1/password_reset/%s", goodToken): generatePostHandler(t, expectedBody, "{}", http.StatusOK),
+		fmt.Sprintf("/v1/password_reset/%s", badToken):  generatePostHandler(t, expectedBody, `{"status":400,"message":"token expired"}`, http.StatusBadRequest),
+	}
+
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("normal usage", func(*testing.T) {
+		err := c.ConfirmPasswordReset(goodToken, "new")
+		assert.Nil(t, err)
+	})
+
+	t.Run("with expired token", func(*testing.T) {
+		err := c.ConfirmPasswordReset(badToken, "new")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestSetUserAdminStatus(t *testing.T) {
+	existentID, nonexistentID := uint64(1), uint64(2)
+	exampleResponseJSON := loadExampleResponse(t, "updated_user")
+	expectedBody := `
+		{
+			"is_admin": true
+		}
+	`
+
+	handlers := map[string]http.HandlerFunc{
+		fmt.Sprintf("/v1/user/%d/admin", existentID):    generatePatchHandler(t, expectedBody, exampleResponseJSON, http.StatusOK),
+		fmt.Sprintf("/v1/user/%d/admin", nonexistentID): generatePatchHandler(t, expectedBody, buildNotFoundUserResponse(nonexistentID), http.StatusNotFound),
+	}
+
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("normal usage", func(*testing.T) {
+		actual, err := c.SetUserAdminStatus(existentID, true)
+		assert.Nil(t, err)
+		assert.True(t, actual.IsAdmin)
+	})
+
+	t.Run("with nonexistent user", func(*testing.T) {
+		_, err := c.SetUserAdminStatus(nonexistentID, true)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,195 @@
+package dairyclient
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dairycart/dairyclient/v1/pricing"
+	"github.com/dairycart/dairymodels/v1"
+)
+
+// FieldError describes a single problem with one field of an input. Field is the field's JSON name.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// ValidationError is returned, before any request is made, when an input fails client-side validation. It lists
+// every problem found rather than just the first.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (ve *ValidationError) Error() string {
+	problems := make([]string, 0, len(ve.Fields))
+	for _, f := range ve.Fields {
+		problems = append(problems, fmt.Sprintf("%s %s", f.Field, f.Message))
+	}
+	return "invalid input: " + strings.Join(problems, "; ")
+}
+
+type validator struct {
+	prefix string
+	fields []FieldError
+}
+
+func (v *validator) add(field string, message string) {
+	v.fields = append(v.fields, FieldError{Field: v.prefix + field, Message: message})
+}
+
+func (v *validator) required(field string, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, "is required")
+	}
+}
+
+func (v *validator) nonNegative(field string, value float32) {
+	if value < 0 {
+		v.add(field, "must not be negative")
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: v.fields}
+}
+
+func (v *validator) dimensions(productWeight, productHeight, productWidth, productLength, packageWeight, packageHeight, packageWidth, packageLength float32) {
+	v.nonNegative("product_weight", productWeight)
+	v.nonNegative("product_height", productHeight)
+	v.nonNegative("product_width", productWidth)
+	v.nonNegative("product_length", productLength)
+	v.nonNegative("package_weight", packageWeight)
+	v.nonNegative("package_height", packageHeight)
+	v.nonNegative("package_width", packageWidth)
+	v.nonNegative("package_length", packageLength)
+}
+
+func (v *validator) prices(price, salePrice, cost float32) {
+	v.nonNegative("price", price)
+	v.nonNegative("sale_price", salePrice)
+	v.nonNegative("cost", cost)
+	if price > 0 && salePrice > price {
+		v.add("sale_price", "must not be above price")
+	}
+}
+
+func (v *validator) productOption(in models.ProductOptionCreationInput) {
+	v.required("name", in.Name)
+	seen := map[string]bool{}
+	for i, value := range in.Values {
+		field := fmt.Sprintf("values[%d]", i)
+		if strings.TrimSpace(value) == "" {
+			v.add(field, "is required")
+		} else if seen[value] {
+			v.add(field, fmt.Sprintf("duplicates value %q", value))
+		}
+		seen[value] = true
+	}
+}
+
+func (v *validator) discountTerms(discountType string, amount float32) {
+	switch discountType {
+	case "", pricing.FlatAmountDiscount:
+	case pricing.PercentageDiscount:
+		if amount > 100 {
+			v.add("amount", "must not be above 100 for a percentage discount")
+		}
+	default:
+		v.add("discount_type", fmt.Sprintf("must be %q or %q", pricing.PercentageDiscount, pricing.FlatAmountDiscount))
+	}
+	v.nonNegative("amount", amount)
+}
+
+func (v *validator) discountDates(startsOn, expiresOn *models.Dairytime) {
+	if startsOn != nil && expiresOn != nil && expiresOn.Time.Before(startsOn.Time) {
+		v.add("expires_on", "must not be before starts_on")
+	}
+}
+
+// Validate checks an input for problems the API would reject, returning a *ValidationError listing all of them,
+// or nil if there are none. It understands ProductCreationInput, ProductUpdateInput, DiscountCreationInput,
+// ProductOptionCreationInput, UserCreationInput, *ProductPatch and *DiscountPatch, and accepts any other input
+// unchecked.
+func Validate(input interface{}) error {
+	v := &validator{}
+
+	switch in := input.(type) {
+	case models.ProductCreationInput:
+		v.required("sku", in.SKU)
+		v.prices(in.Price, in.SalePrice, in.Cost)
+		if in.OnSale && in.SalePrice == 0 {
+			v.add("sale_price", "is required when on_sale is set")
+		}
+		v.dimensions(in.ProductWeight, in.ProductHeight, in.ProductWidth, in.ProductLength, in.PackageWeight, in.PackageHeight, in.PackageWidth, in.PackageLength)
+		for i, o := range in.Options {
+			ov := &validator{prefix: fmt.Sprintf("options[%d].", i)}
+			ov.productOption(o)
+			v.fields = append(v.fields, ov.fields...)
+		}
+
+	case models.ProductUpdateInput:
+		// zero values are left unchanged by the API, so only the values actually being set are checked
+		if in.SKU != "" && strings.TrimSpace(in.SKU) == "" {
+			v.add("sku", "must not be blank")
+		}
+		v.prices(in.Price, in.SalePrice, in.Cost)
+		v.dimensions(in.ProductWeight, in.ProductHeight, in.ProductWidth, in.ProductLength, in.PackageWeight, in.PackageHeight, in.PackageWidth, in.PackageLength)
+
+	case models.DiscountCreationInput:
+		v.required("name", in.Name)
+		v.discountTerms(in.DiscountType, in.Amount)
+		if in.RequiresCode && strings.TrimSpace(in.Code) == "" {
+			v.add("code", "is required when requires_code is set")
+		}
+		v.discountDates(in.StartsOn, in.ExpiresOn)
+
+	case models.ProductOptionCreationInput:
+		v.productOption(in)
+
+	case *ProductPatch:
+		if sku, ok := in.fields["sku"]; ok && (sku == nil || strings.TrimSpace(sku.(string)) == "") {
+			v.add("sku", "must not be blank")
+		}
+		var up models.ProductUpdateInput
+		in.fields.into(&up)
+		v.prices(up.Price, up.SalePrice, up.Cost)
+		v.dimensions(up.ProductWeight, up.ProductHeight, up.ProductWidth, up.ProductLength, up.PackageWeight, up.PackageHeight, up.PackageWidth, up.PackageLength)
+
+	case *DiscountPatch:
+		if name, ok := in.fields["name"]; ok && (name == nil || strings.TrimSpace(name.(string)) == "") {
+			v.add("name", "must not be blank")
+		}
+		var ud models.DiscountUpdateInput
+		in.fields.into(&ud)
+		v.discountTerms(ud.DiscountType, ud.Amount)
+		// a code is only known to be missing if it is cleared in the same patch that requires one
+		if _, ok := in.fields["code"]; ud.RequiresCode && ok && strings.TrimSpace(ud.Code) == "" {
+			v.add("code", "is required when requires_code is set")
+		}
+		v.discountDates(ud.StartsOn, ud.ExpiresOn)
+
+	case models.UserCreationInput:
+		v.required("username", in.Username)
+		v.required("email", in.Email)
+		if in.Email != "" && !strings.Contains(in.Email, "@") {
+			v.add("email", "is not a valid email address")
+		}
+		// a password made of spaces is unusual, but it isn't missing
+		if in.Password == "" {
+			v.add("password", "is required")
+		}
+	}
+
+	return v.err()
+}
+
+// validate runs Validate unless the client has validation turned off
+func (dc *V1Client) validate(input interface{}) error {
+	if dc.SkipValidation {
+		return nil
+	}
+	return Validate(input)
+}
@@ -0,0 +1,161 @@
+package dairyclient_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dairycart/dairyclient/v1"
+	"github.com/dairycart/dairymodels/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validationFields(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	ve, ok := err.(*dairyclient.ValidationError)
+	require.True(t, ok, "Validate should return a *ValidationError")
+
+	var fields []string
+	for _, f := range ve.Fields {
+		fields = append(fields, f.Field)
+	}
+	return fields
+}
+
+func TestValidate(t *testing.T) {
+	starts := &models.Dairytime{Time: time.Date(2017, 12, 10, 0, 0, 0, 0, time.UTC)}
+	expires := &models.Dairytime{Time: starts.Add(-time.Hour)}
+
+	testCases := []struct {
+		name     string
+		input    interface{}
+		expected []string
+	}{
+		{
+			name:  "valid product",
+			input: models.ProductCreationInput{SKU: "sku", Price: 20, SalePrice: 15, OnSale: true},
+		},
+		{
+			name: "invalid product",
+			input: models.ProductCreationInput{
+				SKU:           " ",
+				Price:         10,
+				SalePrice:     12,
+				Cost:          -1,
+				PackageWeight: -2,
+				Options: []models.ProductOptionCreationInput{
+					{Name: "color", Values: []string{"red", "red"}},
+				},
+			},
+			expected: []string{"sku", "cost", "sale_price", "package_weight", "options[0].values[1]"},
+		},
+		{
+			name:     "product on sale without sale price",
+			input:    models.ProductCreationInput{SKU: "sku", Price: 10, OnSale: true},
+			expected: []string{"sale_price"},
+		},
+		{
+			name:  "empty product update",
+			input: models.ProductUpdateInput{},
+		},
+		{
+			name:     "invalid product update",
+			input:    models.ProductUpdateInput{Price: -5},
+			expected: []string{"price"},
+		},
+		{
+			name:  "valid discount",
+			input: models.DiscountCreationInput{Name: "10 percent off", DiscountType: "percentage", Amount: 10, StartsOn: starts},
+		},
+		{
+			name: "invalid discount",
+			input: models.DiscountCreationInput{
+				DiscountType: "percentage",
+				Amount:       110,
+				RequiresCode: true,
+				StartsOn:     starts,
+				ExpiresOn:    expires,
+			},
+			expected: []string{"name", "amount", "code", "expires_on"},
+		},
+		{
+			name:     "unknown discount type",
+			input:    models.DiscountCreationInput{Name: "discount", DiscountType: "bogo"},
+			expected: []string{"discount_type"},
+		},
+		{
+			name:     "invalid product option",
+			input:    models.ProductOptionCreationInput{Values: []string{""}},
+			expected: []string{"name", "values[0]"},
+		},
+		{
+			name:  "valid user",
+			input: models.UserCreationInput{Username: "username", Password: "password", Email: "email@address.com"},
+		},
+		{
+			name:     "invalid user",
+			input:    models.UserCreationInput{Username: "username", Password: "password", Email: "not an email"},
+			expected: []string{"email"},
+		},
+		{
+			name:     "user without credentials",
+			input:    models.UserCreationInput{Username: " ", Email: "email@address.com"},
+			expected: []string{"username", "password"},
+		},
+		{
+			name:  "unknown input",
+			input: struct{}{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := dairyclient.Validate(tc.input)
+			assert.Equal(t, tc.expected, validationFields(t, err))
+		})
+	}
+
+	t.Run("error message", func(t *testing.T) {
+		err := dairyclient.Validate(models.ProductCreationInput{Price: -1})
+		require.NotNil(t, err)
+		assert.Equal(t, "invalid input: sku is required; price must not be negative", err.Error())
+	})
+}
+
+func TestClientValidation(t *testing.T) {
+	var called bool
+	handlers := map[string]http.HandlerFunc{
+		"/v1/product": func(res http.ResponseWriter, req *http.Request) {
+			called = true
+			res.Write([]byte(loadExampleResponse(t, "created_product")))
+		},
+	}
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	invalid := models.ProductCreationInput{Price: -1}
+
+	t.Run("rejects invalid input without a request", func(*testing.T) {
+		called = false
+		_, err := c.CreateProduct(invalid)
+		assert.IsType(t, &dairyclient.ValidationError{}, err)
+		assert.False(t, called, "no request should be made for invalid input")
+	})
+
+	t.Run("with validation disabled", func(*testing.T) {
+		called = false
+		c.SkipValidation = true
+		defer func() { c.SkipValidation = false }()
+
+		_, err := c.CreateProduct(invalid)
+		assert.Nil(t, err)
+		assert.True(t, called, "the request should be left for the server to judge")
+	})
+}
@@ -0,0 +1,245 @@
+// Package webhooks receives Dairycart event notifications, verifying that each one was signed with a shared secret
+// and is recent, and dispatching its payload to handlers registered for its event type.
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dairycart/dairyclient/v1"
+	"github.com/dairycart/dairymodels/v1"
+
+	"github.com/pkg/errors"
+)
+
+// These are the headers Dairycart attaches to every notification
+const (
+	SignatureHeader = "X-Dairycart-Signature"
+	TimestampHeader = "X-Dairycart-Timestamp"
+)
+
+// These are the event types a Receiver knows how to decode
+const (
+	ProductUpdatedEvent    = "product.updated"
+	OrderCreatedEvent      = "order.created"
+	DiscountExhaustedEvent = "discount.exhausted"
+)
+
+const (
+	signaturePrefix = "sha256="
+
+	// DefaultTolerance is how far a notification's timestamp may be from the current time before it is rejected
+	DefaultTolerance = 5 * time.Minute
+	// MaxBodySize is the largest notification body a Receiver will read
+	MaxBodySize = 1 << 20
+)
+
+var (
+	// ErrMissingSignature is returned when a notification has no signature or timestamp
+	ErrMissingSignature = errors.New("notification is missing its signature or timestamp")
+	// ErrInvalidSignature is returned when a notification's signature does not match its body
+	ErrInvalidSignature = errors.New("notification signature is invalid")
+	// ErrStaleTimestamp is returned when a notification's timestamp is outside the receiver's tolerance
+	ErrStaleTimestamp = errors.New("notification timestamp is outside the allowed window")
+	// ErrReplayed is returned when a notification has already been received
+	ErrReplayed = errors.New("notification has already been received")
+)
+
+// Envelope is the outer structure of every notification
+type Envelope struct {
+	ID   string          `json:"id"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Sign computes the signature Dairycart sends for a body at a given timestamp
+func Sign(secret []byte, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%d.", timestamp.Unix())
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Receiver is an http.Handler that verifies and dispatches Dairycart notifications
+type Receiver struct {
+	secret []byte
+
+	// Tolerance is how far a notification's timestamp may be from the current time. It defaults to DefaultTolerance.
+	Tolerance time.Duration
+
+	now func() time.Time
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+
+	productUpdated    []func(models.Product) error
+	orderCreated      []func(dairyclient.Order) error
+	discountExhausted []func(models.Discount) error
+}
+
+// NewReceiver builds a Receiver that trusts notifications signed with secret
+func NewReceiver(secret string) *Receiver {
+	return &Receiver{
+		secret:    []byte(secret),
+		Tolerance: DefaultTolerance,
+		now:       time.Now,
+		seen:      map[string]time.Time{},
+	}
+}
+
+// OnProductUpdated registers a handler for product.updated notifications
+func (r *Receiver) OnProductUpdated(h func(models.Product) error) {
+	r.productUpdated = append(r.productUpdated, h)
+}
+
+// OnOrderCreated registers a handler for order.created notifications
+func (r *Receiver) OnOrderCreated(h func(dairyclient.Order) error) {
+	r.orderCreated = append(r.orderCreated, h)
+}
+
+// OnDiscountExhausted registers a handler for discount.exhausted notifications
+func (r *Receiver) OnDiscountExhausted(h func(models.Discount) error) {
+	r.discountExhausted = append(r.discountExhausted, h)
+}
+
+// Verify checks a notification's signature and timestamp, and that it has not been seen before
+func (r *Receiver) Verify(header http.Header, body []byte) error {
+	signature := header.Get(SignatureHeader)
+	rawTimestamp := header.Get(TimestampHeader)
+	if signature == "" || rawTimestamp == "" {
+		return ErrMissingSignature
+	}
+
+	unix, err := strconv.ParseInt(rawTimestamp, 10, 64)
+	if err != nil {
+		return ErrMissingSignature
+	}
+	timestamp := time.Unix(unix, 0)
+
+	expected := Sign(r.secret, timestamp, body)
+	if !strings.HasPrefix(signature, signaturePrefix) || !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+
+	now := r.now()
+	if timestamp.Before(now.Add(-r.Tolerance)) || timestamp.After(now.Add(r.Tolerance)) {
+		return ErrStaleTimestamp
+	}
+
+	return r.remember(signature, timestamp, now)
+}
+
+// remember records a signature so that the same notification is rejected if it arrives again. Signatures
+// older than the tolerance window are forgotten, since the timestamp check alone rejects them.
+func (r *Receiver) remember(signature string, timestamp time.Time, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for s, t := range r.seen {
+		if t.Before(now.Add(-r.Tolerance)) {
+			delete(r.seen, s)
+		}
+	}
+
+	if _, ok := r.seen[signature]; ok {
+		return ErrReplayed
+	}
+	r.seen[signature] = timestamp
+	return nil
+}
+
+// forget removes a signature from the replay cache, so that a notification whose handling failed can be retried
+func (r *Receiver) forget(signature string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.seen, signature)
+}
+
+// payloadError indicates a notification's data could not be decoded into the type its event calls for
+type payloadError struct {
+	error
+}
+
+func (r *Receiver) dispatch(e Envelope) error {
+	switch e.Type {
+	case ProductUpdatedEvent:
+		p := models.Product{}
+		if err := json.Unmarshal(e.Data, &p); err != nil {
+			return payloadError{errors.Wrap(err, "encountered error decoding product")}
+		}
+		for _, h := range r.productUpdated {
+			if err := h(p); err != nil {
+				return err
+			}
+		}
+	case OrderCreatedEvent:
+		o := dairyclient.Order{}
+		if err := json.Unmarshal(e.Data, &o); err != nil {
+			return payloadError{errors.Wrap(err, "encountered error decoding order")}
+		}
+		for _, h := range r.orderCreated {
+			if err := h(o); err != nil {
+				return err
+			}
+		}
+	case DiscountExhaustedEvent:
+		d := models.Discount{}
+		if err := json.Unmarshal(e.Data, &d); err != nil {
+			return payloadError{errors.Wrap(err, "encountered error decoding discount")}
+		}
+		for _, h := range r.discountExhausted {
+			if err := h(d); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ServeHTTP verifies a notification and dispatches it. Unverifiable notifications get a 401, undecodable ones a
+// 400, and ones whose handlers fail a 500 so that Dairycart retries them; a failed notification is not treated
+// as a replay when it is retried. Unknown event types are acknowledged and ignored.
+func (r *Receiver) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(res, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(res, req.Body, MaxBodySize))
+	if err != nil {
+		http.Error(res, "unable to read body", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.Verify(req.Header, body); err != nil {
+		http.Error(res, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	e := Envelope{}
+	if err := json.Unmarshal(body, &e); err != nil {
+		r.forget(req.Header.Get(SignatureHeader))
+		http.Error(res, "unable to decode notification", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.dispatch(e); err != nil {
+		r.forget(req.Header.Get(SignatureHeader))
+		if _, ok := err.(payloadError); ok {
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	res.WriteHeader(http.StatusNoContent)
+}
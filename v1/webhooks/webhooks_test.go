@@ -0,0 +1,173 @@
+package webhooks_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dairycart/dairyclient/v1"
+	"github.com/dairycart/dairyclient/v1/webhooks"
+	"github.com/dairycart/dairymodels/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const exampleSecret = "shh"
+
+func buildNotification(t *testing.T, secret string, timestamp time.Time, body string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewBufferString(body))
+	req.Header.Set(webhooks.TimestampHeader, strconv.FormatInt(timestamp.Unix(), 10))
+	req.Header.Set(webhooks.SignatureHeader, webhooks.Sign([]byte(secret), timestamp, []byte(body)))
+	return req
+}
+
+func TestReceiver(t *testing.T) {
+	productBody := `{"id": "evt_1", "type": "product.updated", "data": {"id": 1, "sku": "t-shirt-small-red", "quantity": 5}}`
+
+	t.Run("product updated", func(*testing.T) {
+		r := webhooks.NewReceiver(exampleSecret)
+		var received models.Product
+		r.OnProductUpdated(func(p models.Product) error {
+			received = p
+			return nil
+		})
+
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, buildNotification(t, exampleSecret, time.Now(), productBody))
+
+		assert.Equal(t, http.StatusNoContent, res.Code)
+		assert.Equal(t, "t-shirt-small-red", received.SKU)
+	})
+
+	t.Run("order created", func(*testing.T) {
+		r := webhooks.NewReceiver(exampleSecret)
+		var received dairyclient.Order
+		r.OnOrderCreated(func(o dairyclient.Order) error {
+			received = o
+			return nil
+		})
+
+		body := `{"id": "evt_2", "type": "order.created", "data": {"id": 12, "status": "paid", "total": 38.88}}`
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, buildNotification(t, exampleSecret, time.Now(), body))
+
+		assert.Equal(t, http.StatusNoContent, res.Code)
+		assert.Equal(t, uint64(12), received.ID)
+		assert.Equal(t, dairyclient.OrderStatusPaid, received.Status)
+	})
+
+	t.Run("discount exhausted", func(*testing.T) {
+		r := webhooks.NewReceiver(exampleSecret)
+		var received models.Discount
+		r.OnDiscountExhausted(func(d models.Discount) error {
+			received = d
+			return nil
+		})
+
+		body := `{"id": "evt_3", "type": "discount.exhausted", "data": {"id": 3, "name": "gone", "limited_use": true}}`
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, buildNotification(t, exampleSecret, time.Now(), body))
+
+		assert.Equal(t, http.StatusNoContent, res.Code)
+		assert.Equal(t, "gone", received.Name)
+	})
+
+	t.Run("unknown event type", func(*testing.T) {
+		r := webhooks.NewReceiver(exampleSecret)
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, buildNotification(t, exampleSecret, time.Now(), `{"type": "user.created", "data": {}}`))
+		assert.Equal(t, http.StatusNoContent, res.Code)
+	})
+
+	t.Run("wrong secret", func(*testing.T) {
+		r := webhooks.NewReceiver(exampleSecret)
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, buildNotification(t, "wrong", time.Now(), productBody))
+		assert.Equal(t, http.StatusUnauthorized, res.Code)
+	})
+
+	t.Run("missing signature", func(*testing.T) {
+		r := webhooks.NewReceiver(exampleSecret)
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewBufferString(productBody)))
+		assert.Equal(t, http.StatusUnauthorized, res.Code)
+	})
+
+	t.Run("stale timestamp", func(*testing.T) {
+		r := webhooks.NewReceiver(exampleSecret)
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, buildNotification(t, exampleSecret, time.Now().Add(-time.Hour), productBody))
+		assert.Equal(t, http.StatusUnauthorized, res.Code)
+	})
+
+	t.Run("replayed notification", func(*testing.T) {
+		r := webhooks.NewReceiver(exampleSecret)
+		now := time.Now()
+
+		first := httptest.NewRecorder()
+		r.ServeHTTP(first, buildNotification(t, exampleSecret, now, productBody))
+		assert.Equal(t, http.StatusNoContent, first.Code)
+
+		second := httptest.NewRecorder()
+		r.ServeHTTP(second, buildNotification(t, exampleSecret, now, productBody))
+		assert.Equal(t, http.StatusUnauthorized, second.Code)
+	})
+
+	t.Run("failing handler allows retry", func(*testing.T) {
+		r := webhooks.NewReceiver(exampleSecret)
+		calls := 0
+		r.OnProductUpdated(func(models.Product) error {
+			calls++
+			if calls == 1 {
+				return errors.New("arbitrary error")
+			}
+			return nil
+		})
+		now := time.Now()
+
+		first := httptest.NewRecorder()
+		r.ServeHTTP(first, buildNotification(t, exampleSecret, now, productBody))
+		assert.Equal(t, http.StatusInternalServerError, first.Code)
+
+		retry := httptest.NewRecorder()
+		r.ServeHTTP(retry, buildNotification(t, exampleSecret, now, productBody))
+		assert.Equal(t, http.StatusNoContent, retry.Code)
+	})
+
+	t.Run("undecodable payload", func(*testing.T) {
+		r := webhooks.NewReceiver(exampleSecret)
+		body := `{"type": "product.updated", "data": {"id": "not a number"}}`
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, buildNotification(t, exampleSecret, time.Now(), body))
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+	})
+
+	t.Run("wrong method", func(*testing.T) {
+		r := webhooks.NewReceiver(exampleSecret)
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/webhooks", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, res.Code)
+	})
+}
+
+func TestVerify(t *testing.T) {
+	r := webhooks.NewReceiver(exampleSecret)
+	body := []byte(`{}`)
+	now := time.Now()
+
+	header := http.Header{}
+	header.Set(webhooks.TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	header.Set(webhooks.SignatureHeader, webhooks.Sign([]byte(exampleSecret), now, body))
+
+	assert.Nil(t, r.Verify(header, body))
+	assert.Equal(t, webhooks.ErrReplayed, r.Verify(header, body))
+	assert.Equal(t, webhooks.ErrInvalidSignature, r.Verify(header, []byte(`{"tampered": true}`)))
+
+	header.Set(webhooks.TimestampHeader, "yesterday")
+	assert.Equal(t, webhooks.ErrMissingSignature, r.Verify(header, body))
+}